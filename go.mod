@@ -2,6 +2,10 @@ module github.com/aaronellington/projectl
 
 go 1.17
 
-require golang.org/x/mod v0.4.2
+require (
+	github.com/BurntSushi/toml v1.3.2
+	golang.org/x/mod v0.4.2
+	gopkg.in/yaml.v3 v3.0.1
+)
 
 require golang.org/x/xerrors v0.0.0-20191011141410-1b5146add898 // indirect
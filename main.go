@@ -2,13 +2,14 @@ package main
 
 import (
 	"log"
+	"os"
 
 	"github.com/aaronellington/projectl/pkg/projectl"
 )
 
 func main() {
 	app := &projectl.App{}
-	if err := app.Execute(); err != nil {
+	if err := app.Run(os.Args[1:]); err != nil {
 		log.Fatal(err)
 	}
 }
@@ -3,7 +3,6 @@ package configuration
 import (
 	"encoding/json"
 	"errors"
-	"fmt"
 	"os"
 )
 
@@ -12,33 +11,215 @@ var (
 	ErrInvalidConfigFile = errors.New("invalid config file")
 )
 
-// NewConfig creates a new config object with the defaults already set
-func NewConfig(configFilePath string) (*Config, error) {
-	config := &Config{}
+// ConfigFileNames are the supported config files, the first one that exists is used
+var ConfigFileNames = []string{
+	".projectl.json",
+	".projectl.yaml",
+	".projectl.yml",
+	".projectl.toml",
+}
 
-	// Open the config file
-	configFile, err := os.Open(configFilePath)
-	if err != nil {
-		return &Config{}, nil
+// FindConfigFile gets the config file of the project, .projectl.json when there is none yet
+func FindConfigFile() string {
+	for _, configFileName := range ConfigFileNames {
+		if _, err := os.Stat(configFileName); err == nil {
+			return configFileName
+		}
 	}
-	defer configFile.Close()
 
-	// Parse the config file
-	decoder := json.NewDecoder(configFile)
-	if err := decoder.Decode(config); err != nil {
-		return nil, fmt.Errorf("%w: %s", ErrInvalidConfigFile, configFilePath)
+	return ConfigFileNames[0]
+}
+
+// applyDeprecatedKeys moves the keys that predate the generators config into it
+func (config *Config) applyDeprecatedKeys() {
+	if config.CustomDockerFile && config.Generators.Dockerfile.Enabled == nil {
+		disabled := false
+		config.Generators.Dockerfile.Enabled = &disabled
 	}
 
-	return config, nil
+	if config.GoHTTP {
+		config.Generators.Makefile.GoHTTP = true
+	}
 }
 
 // Config of projectl
 type Config struct {
-	Gitignore        []string `json:"gitignore"`
-	DistedFiles      []string `json:"disted_files"`
-	DockerName       string   `json:"docker_name"`
-	DockerTarget     string   `json:"docker_target"`
-	DockerPort       int      `json:"docker_port"`
-	GoHTTP           bool     `json:"go_http"`
-	CustomDockerFile bool     `json:"custom_dockerfile"`
+	Schema           string                       `json:"$schema" description:"URL or path of the JSON Schema, for editors"`
+	Version          int                          `json:"version" description:"Version of the config format, projectl config migrate upgrades older configs" minimum:"1"`
+	Extends          string                       `json:"extends" description:"Path of a base config merged under this one, relative to this file, environment variables are expanded"`
+	Gitignore        []string                     `json:"gitignore" description:"Extra .gitignore entries for the project, appended to the base config's" merge:"append"`
+	DistedFiles      []string                     `json:"disted_files" description:"Config files copied from their .dist, .example, .sample or .template version by make copy-config, globs are allowed and a pattern starting with ! removes a default, appended to the base config's" merge:"append"`
+	DockerName       string                       `json:"docker_name" description:"Image name, a Dockerfile is only generated when this is set"`
+	DockerTarget     string                       `json:"docker_target" description:"Go target binary the Docker image runs, defaults to the first one detected"`
+	DockerPort       int                          `json:"docker_port" description:"Port exposed by the Docker image" minimum:"1" maximum:"65535"`
+	GoHTTP           bool                         `json:"go_http" description:"Deprecated, use generators.makefile.go_http" deprecated:"generators.makefile.go_http"`
+	CustomDockerFile bool                         `json:"custom_dockerfile" description:"Deprecated, use generators.dockerfile.enabled" deprecated:"generators.dockerfile.enabled"`
+	Generators       GeneratorsConfig             `json:"generators" description:"Turns generators off and passes their options"`
+	NodeVersion      string                       `json:"node_version" description:"Node version, overrides .nvmrc, .node-version, .tool-versions and engines.node" default:"16"`
+	Eslint           EslintConfig                 `json:"eslint" description:"Deprecated, use generators.eslint" deprecated:"generators.eslint"`
+	TypeScript       TypeScriptConfig             `json:"typescript" description:"Deprecated, use generators.typescript" deprecated:"generators.typescript"`
+	Prettier         map[string]interface{}       `json:"prettier" description:"Deprecated, use generators.prettier.options" deprecated:"generators.prettier.options"`
+	Stylelint        StylelintConfig              `json:"stylelint" description:"Deprecated, use generators.stylelint" deprecated:"generators.stylelint"`
+	Editorconfig     map[string]map[string]string `json:"editorconfig" description:"Deprecated, use generators.editorconfig.sections" deprecated:"generators.editorconfig.sections"`
+	PHPCSFixer       PHPCSFixerConfig             `json:"php_cs_fixer" description:"Deprecated, use generators.php_config.php_cs_fixer" deprecated:"generators.php_config.php_cs_fixer"`
+	PHPStan          PHPStanConfig                `json:"phpstan" description:"Deprecated, use generators.php_config.phpstan" deprecated:"generators.php_config.phpstan"`
+	PHPUnit          PHPUnitConfig                `json:"phpunit" description:"Deprecated, use generators.php_config.phpunit" deprecated:"generators.php_config.phpunit"`
+}
+
+// GeneratorsConfig has an entry for every generator, they're all enabled unless turned off.
+// The top level keys describe the project, like its Docker image and the files to ignore,
+// and any generator may use them, while the options that only shape the file of a single
+// generator live in its entry here
+type GeneratorsConfig struct {
+	Gitignore      GeneratorConfig             `json:"gitignore" description:"The .gitignore file"`
+	Makefile       MakefileGeneratorConfig     `json:"makefile" description:"The Makefile"`
+	GithubWorkflow GeneratorConfig             `json:"github_workflow" description:"The GitHub Actions workflow"`
+	Eslint         EslintConfig                `json:"eslint" description:"The ESLint config"`
+	TypeScript     TypeScriptConfig            `json:"typescript" description:"The tsconfig.json file"`
+	Prettier       PrettierGeneratorConfig     `json:"prettier" description:"The Prettier config"`
+	Stylelint      StylelintConfig             `json:"stylelint" description:"The stylelint config"`
+	Editorconfig   EditorconfigGeneratorConfig `json:"editorconfig" description:"The .editorconfig file"`
+	PHPConfig      PHPConfigGeneratorConfig    `json:"php_config" description:"The php-cs-fixer, PHP_CodeSniffer, phpstan and phpunit configs"`
+	Dockerfile     GeneratorConfig             `json:"dockerfile" description:"The Dockerfile, only generated when docker_name is set"`
+}
+
+// GeneratorConfig is the config of a generator without options
+type GeneratorConfig struct {
+	Enabled *bool `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (generatorConfig GeneratorConfig) IsEnabled() bool {
+	return generatorConfig.Enabled == nil || *generatorConfig.Enabled
+}
+
+// MakefileGeneratorConfig is the config of the Makefile generator
+type MakefileGeneratorConfig struct {
+	Enabled *bool `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+	GoHTTP  bool  `json:"go_http" description:"Add a watch-go target that live reloads the HTTP server on docker_port" default:"false"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (generatorConfig MakefileGeneratorConfig) IsEnabled() bool {
+	return generatorConfig.Enabled == nil || *generatorConfig.Enabled
+}
+
+// PrettierGeneratorConfig is the config of the Prettier generator
+type PrettierGeneratorConfig struct {
+	Enabled *bool                  `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+	Options map[string]interface{} `json:"options" description:"Prettier options merged over the defaults"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (generatorConfig PrettierGeneratorConfig) IsEnabled() bool {
+	return generatorConfig.Enabled == nil || *generatorConfig.Enabled
+}
+
+// EditorconfigGeneratorConfig is the config of the EditorConfig generator
+type EditorconfigGeneratorConfig struct {
+	Enabled  *bool                        `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+	Sections map[string]map[string]string `json:"sections" description:"EditorConfig properties by section glob, merged over the defaults"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (generatorConfig EditorconfigGeneratorConfig) IsEnabled() bool {
+	return generatorConfig.Enabled == nil || *generatorConfig.Enabled
+}
+
+// PHPConfigGeneratorConfig is the config of the generator of the PHP tool configs
+type PHPConfigGeneratorConfig struct {
+	Enabled    *bool            `json:"enabled" description:"Set to false to keep the files as they are" default:"true"`
+	PHPCSFixer PHPCSFixerConfig `json:"php_cs_fixer" description:"Config for the generated php-cs-fixer config"`
+	PHPStan    PHPStanConfig    `json:"phpstan" description:"Config for the generated phpstan.neon.dist"`
+	PHPUnit    PHPUnitConfig    `json:"phpunit" description:"Config for the generated phpunit.xml.dist"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (generatorConfig PHPConfigGeneratorConfig) IsEnabled() bool {
+	return generatorConfig.Enabled == nil || *generatorConfig.Enabled
+}
+
+// EslintConfig is merged onto the generated ESLint config, extends, ignore
+// patterns and overrides are appended while rules and env replace by name
+type EslintConfig struct {
+	Enabled        *bool                    `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+	Extends        []string                 `json:"extends" description:"Shareable configs appended to the generated extends" merge:"append"`
+	Rules          map[string]interface{}   `json:"rules" description:"Rules replacing the generated ones by name"`
+	Env            map[string]bool          `json:"env" description:"Environments replacing the generated ones by name"`
+	IgnorePatterns []string                 `json:"ignore_patterns" description:"Patterns of files ESLint skips" merge:"append"`
+	Overrides      []map[string]interface{} `json:"overrides" description:"ESLint overrides appended to the generated ones" merge:"append"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (eslintConfig EslintConfig) IsEnabled() bool {
+	return eslintConfig.Enabled == nil || *eslintConfig.Enabled
+}
+
+// TypeScriptConfig is merged onto the generated tsconfig.json, compiler options
+// replace by name while include and exclude replace the generated lists
+type TypeScriptConfig struct {
+	Enabled         *bool                  `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+	CompilerOptions map[string]interface{} `json:"compiler_options" description:"Compiler options replacing the generated ones by name"`
+	Include         []string               `json:"include" description:"Files to compile, replaces the generated list"`
+	Exclude         []string               `json:"exclude" description:"Files to skip, replaces the generated list"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (typeScriptConfig TypeScriptConfig) IsEnabled() bool {
+	return typeScriptConfig.Enabled == nil || *typeScriptConfig.Enabled
+}
+
+// StylelintConfig is merged onto the generated stylelint config, extends
+// are appended while rules replace by name
+type StylelintConfig struct {
+	Enabled *bool                  `json:"enabled" description:"Set to false to keep the file as it is" default:"true"`
+	Extends []string               `json:"extends" description:"Shareable configs appended to the generated extends" merge:"append"`
+	Rules   map[string]interface{} `json:"rules" description:"Rules replacing the generated ones by name"`
+}
+
+// IsEnabled checks if the generator wasn't turned off
+func (stylelintConfig StylelintConfig) IsEnabled() bool {
+	return stylelintConfig.Enabled == nil || *stylelintConfig.Enabled
+}
+
+// PHPCSFixerConfig is the config for the generated php-cs-fixer config,
+// rules are merged over the house defaults and paths replace the autoload directories
+type PHPCSFixerConfig struct {
+	Rules   map[string]interface{} `json:"rules" description:"Rules merged over the house defaults"`
+	Paths   []string               `json:"paths" description:"Directories to fix, defaults to the composer autoload directories"`
+	Exclude []string               `json:"exclude" description:"Directories to skip" merge:"append"`
+}
+
+// PHPStanConfig is the config for the generated phpstan.neon.dist
+type PHPStanConfig struct {
+	Level        PHPStanLevel `json:"level" description:"Rule level" default:"max"`
+	ExcludePaths []string     `json:"exclude_paths" description:"Paths phpstan skips" merge:"append"`
+}
+
+// PHPStanLevel is a phpstan rule level, 0 through 9 or max
+type PHPStanLevel string
+
+// UnmarshalJSON accepts the level as either a number or a string
+func (level *PHPStanLevel) UnmarshalJSON(data []byte) error {
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err == nil {
+		*level = PHPStanLevel(number.String())
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*string)(level))
+}
+
+// JSONSchema lists the levels phpstan accepts
+func (level PHPStanLevel) JSONSchema() *Schema {
+	return &Schema{
+		Enum: []interface{}{0, 1, 2, 3, 4, 5, 6, 7, 8, 9, "max"},
+	}
+}
+
+// PHPUnitConfig is the config for the generated phpunit.xml.dist
+type PHPUnitConfig struct {
+	Bootstrap      string `json:"bootstrap" description:"Script run before the tests" default:"vendor/autoload.php"`
+	CoverageClover string `json:"coverage_clover" description:"Path of the clover coverage report, none when empty"`
 }
@@ -0,0 +1,202 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// configFormat is the syntax of a config file
+type configFormat int
+
+const (
+	formatJSON configFormat = iota
+	formatYAML
+	formatTOML
+)
+
+var (
+	unknownFieldMatcher = regexp.MustCompile(`^json: unknown field "(.*)"$`)
+	yamlLineMatcher     = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+	tomlPrefixMatcher   = regexp.MustCompile(`^toml: line \d+( \(last key "[^"]*"\))?: `)
+)
+
+// yamlSyntaxError keeps the line of a YAML parse error apart from the JSON errors
+type yamlSyntaxError struct {
+	line    string
+	message string
+}
+
+func (syntaxError *yamlSyntaxError) Error() string {
+	return syntaxError.message
+}
+
+// toJSON converts the file to JSON, so every format shares the strict
+// decoding and the custom unmarshalers
+func (format configFormat) toJSON(fileBytes []byte) ([]byte, error) {
+	var values interface{}
+
+	switch format {
+	case formatYAML:
+		if err := yaml.Unmarshal(fileBytes, &values); err != nil {
+			if match := yamlLineMatcher.FindStringSubmatch(err.Error()); match != nil {
+				return nil, &yamlSyntaxError{line: match[1], message: match[2]}
+			}
+
+			return nil, err
+		}
+
+		// An empty YAML file is an empty config
+		if values == nil {
+			values = map[string]interface{}{}
+		}
+	case formatTOML:
+		tomlValues := map[string]interface{}{}
+		if _, err := toml.Decode(string(fileBytes), &tomlValues); err != nil {
+			return nil, err
+		}
+		values = tomlValues
+	default:
+		return fileBytes, nil
+	}
+
+	return json.Marshal(values)
+}
+
+// decodeStrict decodes JSON into the config, a typo in a key should fail instead of being silently ignored
+func decodeStrict(jsonBytes []byte, config *Config) error {
+	decoder := json.NewDecoder(bytes.NewReader(jsonBytes))
+	decoder.DisallowUnknownFields()
+
+	return decoder.Decode(config)
+}
+
+// formatOf chooses the decoder by the extension of the config file
+func formatOf(configFilePath string) configFormat {
+	switch filepath.Ext(configFilePath) {
+	case ".yaml", ".yml":
+		return formatYAML
+	case ".toml":
+		return formatTOML
+	}
+
+	return formatJSON
+}
+
+// describeError turns a decode error into a message with the position and key at fault
+func (format configFormat) describeError(configFilePath string, fileBytes []byte, err error) string {
+	var syntaxError *json.SyntaxError
+	var typeError *json.UnmarshalTypeError
+	var yamlError *yamlSyntaxError
+	var tomlError toml.ParseError
+
+	switch {
+	case errors.As(err, &yamlError):
+		return fmt.Sprintf("%s:%s: %s", configFilePath, yamlError.line, yamlError.message)
+	case errors.As(err, &tomlError):
+		line, column := position(fileBytes, int64(tomlError.Position.Start))
+
+		message := tomlError.Message
+		if message == "" {
+			message = tomlPrefixMatcher.ReplaceAllString(tomlError.Error(), "")
+		}
+
+		return fmt.Sprintf("%s:%d:%d: %s", configFilePath, line, column, message)
+	case errors.As(err, &syntaxError):
+		line, column := position(fileBytes, syntaxError.Offset)
+
+		return fmt.Sprintf("%s:%d:%d: %s", configFilePath, line, column, syntaxError.Error())
+	case errors.As(err, &typeError):
+		message := fmt.Sprintf("key %q must be %s, got %s", typeError.Field, jsonTypeName(typeError.Type), typeError.Value)
+
+		// The offset is only meaningful when the file itself was the JSON that was decoded
+		if format == formatJSON {
+			line, column := position(fileBytes, typeError.Offset)
+
+			return fmt.Sprintf("%s:%d:%d: %s", configFilePath, line, column, message)
+		}
+
+		keys := strings.Split(typeError.Field, ".")
+
+		return format.describeKey(configFilePath, fileBytes, keys[len(keys)-1], message)
+	case errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		line, column := position(fileBytes, int64(len(fileBytes)))
+
+		return fmt.Sprintf("%s:%d:%d: unexpected end of file", configFilePath, line, column)
+	}
+
+	// The decoder doesn't say where an unknown key is, so find its first use as a key
+	if match := unknownFieldMatcher.FindStringSubmatch(err.Error()); match != nil {
+		return format.describeKey(configFilePath, fileBytes, match[1], fmt.Sprintf("unknown key %q", match[1]))
+	}
+
+	return fmt.Sprintf("%s: %s", configFilePath, err.Error())
+}
+
+// describeKey prefixes the message with the position of the first use of the key
+func (format configFormat) describeKey(configFilePath string, fileBytes []byte, key string, message string) string {
+	quotedKey := `["']?` + regexp.QuoteMeta(key) + `["']?`
+
+	keyMatcher := regexp.MustCompile(`"` + regexp.QuoteMeta(key) + `"\s*:`)
+	switch format {
+	case formatYAML:
+		keyMatcher = regexp.MustCompile(`(?m)^[ \t-]*` + quotedKey + `[ \t]*:`)
+	case formatTOML:
+		keyMatcher = regexp.MustCompile(`(?m)^[ \t]*(` + quotedKey + `[ \t]*=|\[+([^\]]*\.)?` + quotedKey + `\]+)`)
+	}
+
+	location := keyMatcher.FindIndex(fileBytes)
+	if location == nil {
+		return fmt.Sprintf("%s: %s", configFilePath, message)
+	}
+
+	// Skip the indentation so the column points at the key itself
+	offset := location[0]
+	for offset < len(fileBytes) && strings.ContainsRune(" \t-", rune(fileBytes[offset])) {
+		offset++
+	}
+
+	line, column := position(fileBytes, int64(offset))
+
+	return fmt.Sprintf("%s:%d:%d: %s", configFilePath, line, column, message)
+}
+
+// position converts a byte offset to a 1-based line and column
+func position(fileBytes []byte, offset int64) (int, int) {
+	if offset > int64(len(fileBytes)) {
+		offset = int64(len(fileBytes))
+	}
+
+	before := fileBytes[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := len(before) - bytes.LastIndexByte(before, '\n')
+
+	return line, column
+}
+
+// jsonTypeName gets the JSON name of the type a config key expects
+func jsonTypeName(goType reflect.Type) string {
+	switch goType.Kind() {
+	case reflect.Bool:
+		return "a boolean"
+	case reflect.String:
+		return "a string"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Float32, reflect.Float64:
+		return "a number"
+	case reflect.Slice, reflect.Array:
+		return "an array"
+	case reflect.Map, reflect.Struct:
+		return "an object"
+	}
+
+	return strings.ToLower(goType.String())
+}
@@ -0,0 +1,165 @@
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+)
+
+// configLayer is one source of config values, merged over the layers before it
+type configLayer struct {
+	source string
+	values map[string]interface{}
+	// replaceLists is set for overrides, they replace the lists tagged merge:"append" too
+	replaceLists bool
+}
+
+// loadLayers decodes a config file and the base configs it extends, base first,
+// every file is strictly decoded on its own so errors point at the file at fault
+func loadLayers(configFilePath string, chain []string) ([]configLayer, error) {
+	fileBytes, err := ioutil.ReadFile(configFilePath)
+	if err != nil {
+		if len(chain) == 0 {
+			return nil, fmt.Errorf("%w while reading %s", err, configFilePath)
+		}
+
+		return nil, fmt.Errorf("%w: %s extends %s which can't be read", ErrInvalidConfigFile, chain[len(chain)-1], configFilePath)
+	}
+
+	format := formatOf(configFilePath)
+
+	jsonBytes, err := format.toJSON(fileBytes)
+	if err == nil {
+		err = decodeStrict(jsonBytes, &Config{})
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidConfigFile, format.describeError(configFilePath, fileBytes, err))
+	}
+
+	values := map[string]interface{}{}
+	if err := json.Unmarshal(jsonBytes, &values); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidConfigFile, configFilePath, err.Error())
+	}
+
+	layer := configLayer{
+		source: configFilePath,
+		values: values,
+	}
+
+	extends, _ := values["extends"].(string)
+	if extends == "" {
+		return []configLayer{layer}, nil
+	}
+
+	basePath := os.ExpandEnv(extends)
+	if !filepath.IsAbs(basePath) {
+		basePath = filepath.Join(filepath.Dir(configFilePath), basePath)
+	}
+
+	chain = append(chain, configFilePath)
+	for _, extendedPath := range chain {
+		if filepath.Clean(extendedPath) == filepath.Clean(basePath) {
+			return nil, fmt.Errorf("%w: extends loop %s -> %s", ErrInvalidConfigFile, strings.Join(chain, " -> "), basePath)
+		}
+	}
+
+	baseLayers, err := loadLayers(basePath, chain)
+	if err != nil {
+		return nil, err
+	}
+
+	// The base's own extends is already resolved, the project's is kept to show where it came from
+	delete(baseLayers[len(baseLayers)-1].values, "extends")
+
+	return append(baseLayers, layer), nil
+}
+
+// mergeValues merges the project values over the base values, objects of the
+// config are merged by key, lists tagged merge:"append" are appended to the
+// base list unless replaceLists is set and anything else set in the project
+// replaces the base value
+func mergeValues(goType reflect.Type, baseValue interface{}, projectValue interface{}, appendLists bool, replaceLists bool) interface{} {
+	switch goType.Kind() {
+	case reflect.Struct, reflect.Map:
+		baseObject, baseOk := baseValue.(map[string]interface{})
+		projectObject, projectOk := projectValue.(map[string]interface{})
+		if !baseOk || !projectOk {
+			return projectValue
+		}
+
+		merged := map[string]interface{}{}
+		for key, value := range baseObject {
+			merged[key] = value
+		}
+
+		for key, value := range projectObject {
+			base, exists := merged[key]
+			if !exists {
+				merged[key] = value
+				continue
+			}
+
+			// Values of free-form maps, like ESLint rules, replace by name
+			if goType.Kind() == reflect.Map {
+				if goType.Elem().Kind() == reflect.Interface {
+					merged[key] = value
+				} else {
+					merged[key] = mergeValues(goType.Elem(), base, value, false, replaceLists)
+				}
+
+				continue
+			}
+
+			field, ok := fieldByJSONName(goType, key)
+			if !ok {
+				merged[key] = value
+				continue
+			}
+
+			merged[key] = mergeValues(field.Type, base, value, field.Tag.Get("merge") == "append" && !replaceLists, replaceLists)
+		}
+
+		return merged
+	case reflect.Slice:
+		baseList, baseOk := baseValue.([]interface{})
+		projectList, projectOk := projectValue.([]interface{})
+		if !appendLists || !baseOk || !projectOk {
+			return projectValue
+		}
+
+		merged := append([]interface{}{}, baseList...)
+		for _, value := range projectList {
+			if !containsValue(merged, value) {
+				merged = append(merged, value)
+			}
+		}
+
+		return merged
+	}
+
+	return projectValue
+}
+
+func fieldByJSONName(goType reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < goType.NumField(); i++ {
+		if jsonName(goType.Field(i)) == name {
+			return goType.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+func containsValue(values []interface{}, value interface{}) bool {
+	for _, existingValue := range values {
+		if reflect.DeepEqual(existingValue, value) {
+			return true
+		}
+	}
+
+	return false
+}
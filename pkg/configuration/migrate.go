@@ -0,0 +1,424 @@
+package configuration
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CurrentVersion is the version of the config format, configs without a version are version 1
+const CurrentVersion = 3
+
+// Errors
+var (
+	ErrMigrationUnsupported = errors.New("config can't be migrated automatically")
+)
+
+// migration upgrades a config document to its version, returning the changes it made,
+// it only changes the keys it finds so it can run again on a config that is already
+// at its version but still uses a deprecated key
+type migration struct {
+	version int
+	apply   func(document *yaml.Node) []string
+}
+
+var migrations = []migration{
+	{
+		version: 2,
+		apply:   migrateToGeneratorsConfig,
+	},
+	{
+		version: 3,
+		apply:   migrateToGeneratorOptions,
+	},
+}
+
+// movedKeys are the generator options that moved from the top level into the generators config
+var movedKeys = []struct {
+	from string
+	to   []string
+}{
+	{from: "eslint", to: []string{"generators", "eslint"}},
+	{from: "typescript", to: []string{"generators", "typescript"}},
+	{from: "prettier", to: []string{"generators", "prettier", "options"}},
+	{from: "stylelint", to: []string{"generators", "stylelint"}},
+	{from: "editorconfig", to: []string{"generators", "editorconfig", "sections"}},
+	{from: "php_cs_fixer", to: []string{"generators", "php_config", "php_cs_fixer"}},
+	{from: "phpstan", to: []string{"generators", "php_config", "phpstan"}},
+	{from: "phpunit", to: []string{"generators", "php_config", "phpunit"}},
+}
+
+var indentMatcher = regexp.MustCompile(`(?m)^([ \t]+)\S`)
+
+// MigrateConfig rewrites a config file to the current version, keeping the key
+// order, the formatting of JSON and the comments of YAML
+func MigrateConfig(configFilePath string, fileBytes []byte) ([]byte, []string, error) {
+	format := formatOf(configFilePath)
+
+	// TOML is read through its JSON form to find out which changes it needs
+	documentBytes := fileBytes
+	if format == formatTOML {
+		jsonBytes, err := format.toJSON(fileBytes)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: %s", ErrInvalidConfigFile, format.describeError(configFilePath, fileBytes, err))
+		}
+		documentBytes = jsonBytes
+	}
+
+	document := &yaml.Node{}
+	if err := yaml.Unmarshal(documentBytes, document); err != nil {
+		return nil, nil, fmt.Errorf("%w: %s: %s", ErrInvalidConfigFile, configFilePath, err.Error())
+	}
+
+	// An empty file has nothing to migrate
+	if len(document.Content) == 0 || document.Content[0].Kind != yaml.MappingNode {
+		return fileBytes, []string{}, nil
+	}
+	root := document.Content[0]
+
+	version := 1
+	if versionNode, _ := mappingValue(root, "version"); versionNode != nil {
+		version, _ = strconv.Atoi(versionNode.Value)
+	}
+
+	originalVersion := version
+	changes := []string{}
+	for _, migration := range migrations {
+		changes = append(changes, migration.apply(root)...)
+
+		if migration.version > version {
+			version = migration.version
+		}
+	}
+
+	if version != originalVersion {
+		setVersion(root, version)
+		changes = append(changes, fmt.Sprintf("set version to %d", version))
+	}
+
+	if len(changes) == 0 {
+		return fileBytes, changes, nil
+	}
+
+	indent := "  "
+	if match := indentMatcher.FindSubmatch(fileBytes); match != nil {
+		indent = string(match[1])
+	}
+
+	switch format {
+	case formatJSON:
+		return []byte(renderJSONNode(root, indent, "") + "\n"), changes, nil
+	case formatYAML:
+		buffer := &bytes.Buffer{}
+		encoder := yaml.NewEncoder(buffer)
+		encoder.SetIndent(len(strings.ReplaceAll(indent, "\t", "  ")))
+		if err := encoder.Encode(document); err != nil {
+			return nil, nil, err
+		}
+
+		return buffer.Bytes(), changes, nil
+	}
+
+	// There is no TOML library that keeps comments, so the changes are left to be made by hand
+	return nil, changes, fmt.Errorf("%w: %s", ErrMigrationUnsupported, configFilePath)
+}
+
+// migrateToGeneratorsConfig moves custom_dockerfile and go_http into the generators config
+func migrateToGeneratorsConfig(root *yaml.Node) []string {
+	changes := []string{}
+
+	if valueNode, index := mappingValue(root, "custom_dockerfile"); valueNode != nil {
+		removeKey(root, "custom_dockerfile")
+
+		// Like the loader, an explicit generators.dockerfile.enabled wins over custom_dockerfile
+		dockerfilePath := []string{"generators", "dockerfile", "enabled"}
+		if pathValue(root, dockerfilePath) != nil {
+			changes = append(changes, "removed custom_dockerfile, generators.dockerfile.enabled is already set")
+		} else {
+			if valueNode.Value == "true" {
+				setPath(root, dockerfilePath, scalarNode("!!bool", "false"), index)
+			}
+			changes = append(changes, "moved custom_dockerfile to generators.dockerfile.enabled")
+		}
+	}
+
+	if valueNode, index := mappingValue(root, "go_http"); valueNode != nil {
+		removeKey(root, "go_http")
+		if valueNode.Value == "true" {
+			setPath(root, []string{"generators", "makefile", "go_http"}, scalarNode("!!bool", "true"), index)
+		}
+		changes = append(changes, "moved go_http to generators.makefile.go_http")
+	}
+
+	return changes
+}
+
+// migrateToGeneratorOptions moves the generator options into the generators config
+func migrateToGeneratorOptions(root *yaml.Node) []string {
+	changes := []string{}
+
+	for _, movedKey := range movedKeys {
+		valueNode, index := mappingValue(root, movedKey.from)
+		if valueNode == nil {
+			continue
+		}
+		removeKey(root, movedKey.from)
+
+		// Like the loader, the options already in the generators config win over the old ones
+		to := strings.Join(movedKey.to, ".")
+		existing := pathValue(root, movedKey.to)
+		switch {
+		case existing == nil:
+			setPath(root, movedKey.to, valueNode, index)
+		case existing.Kind == yaml.MappingNode && valueNode.Kind == yaml.MappingNode:
+			mergeMappingNodes(existing, valueNode)
+		default:
+			changes = append(changes, fmt.Sprintf("removed %s, %s is already set", movedKey.from, to))
+			continue
+		}
+
+		changes = append(changes, fmt.Sprintf("moved %s to %s", movedKey.from, to))
+	}
+
+	return changes
+}
+
+// moveDeprecatedValues moves the generator options of a config layer from the top level
+// into the generators config, the options already in the generators config win by name
+func moveDeprecatedValues(values map[string]interface{}) {
+	for _, movedKey := range movedKeys {
+		value, exists := values[movedKey.from]
+		if !exists {
+			continue
+		}
+		delete(values, movedKey.from)
+
+		goType, err := typeAtPath(reflect.TypeOf(Config{}), movedKey.to)
+		if err != nil {
+			continue
+		}
+
+		current := values
+		for _, key := range movedKey.to[:len(movedKey.to)-1] {
+			next, isObject := current[key].(map[string]interface{})
+			if !isObject {
+				next = map[string]interface{}{}
+				current[key] = next
+			}
+			current = next
+		}
+
+		last := movedKey.to[len(movedKey.to)-1]
+		if existing, exists := current[last]; exists {
+			value = mergeValues(goType, value, existing, false, false)
+		}
+		current[last] = value
+	}
+}
+
+// deprecationWarnings lists the deprecated keys the values use, along with their replacement
+func deprecationWarnings(goType reflect.Type, values map[string]interface{}, path string) []string {
+	warnings := []string{}
+
+	for key, value := range values {
+		field, ok := fieldByJSONName(goType, key)
+		if !ok {
+			continue
+		}
+
+		if replacement := field.Tag.Get("deprecated"); replacement != "" {
+			warnings = append(warnings, fmt.Sprintf("%s is deprecated, use %s instead or run projectl config migrate", joinPath(path, key), replacement))
+		}
+
+		if object, isObject := value.(map[string]interface{}); isObject && field.Type.Kind() == reflect.Struct {
+			warnings = append(warnings, deprecationWarnings(field.Type, object, joinPath(path, key))...)
+		}
+	}
+
+	sort.Strings(warnings)
+
+	return warnings
+}
+
+// mappingValue finds the value of a key and the position of the key in the mapping
+func mappingValue(mapping *yaml.Node, key string) (*yaml.Node, int) {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value == key {
+			return mapping.Content[i+1], i
+		}
+	}
+
+	return nil, len(mapping.Content)
+}
+
+// pathValue finds a nested value, or nil when a key along the path is missing
+func pathValue(mapping *yaml.Node, keys []string) *yaml.Node {
+	value, _ := mappingValue(mapping, keys[0])
+	if value == nil || len(keys) == 1 {
+		return value
+	}
+
+	if value.Kind != yaml.MappingNode {
+		return nil
+	}
+
+	return pathValue(value, keys[1:])
+}
+
+// mergeMappingNodes adds the keys of the source the target doesn't have, the target wins by name
+func mergeMappingNodes(target *yaml.Node, source *yaml.Node) {
+	for i := 0; i+1 < len(source.Content); i += 2 {
+		existing, _ := mappingValue(target, source.Content[i].Value)
+		switch {
+		case existing == nil:
+			// Keep a mapping that was on a single line on a single line
+			keyNode := *source.Content[i]
+			keyNode.Line = target.Line
+			target.Content = append(target.Content, &keyNode, source.Content[i+1])
+		case existing.Kind == yaml.MappingNode && source.Content[i+1].Kind == yaml.MappingNode:
+			mergeMappingNodes(existing, source.Content[i+1])
+		}
+	}
+}
+
+func removeKey(mapping *yaml.Node, key string) {
+	if _, index := mappingValue(mapping, key); index < len(mapping.Content) {
+		mapping.Content = append(mapping.Content[:index], mapping.Content[index+2:]...)
+	}
+}
+
+// setPath sets a nested value, a missing top level key is added at the index
+func setPath(mapping *yaml.Node, keys []string, value *yaml.Node, index int) {
+	existing, _ := mappingValue(mapping, keys[0])
+
+	if len(keys) == 1 {
+		if existing != nil {
+			*existing = *value
+
+			return
+		}
+
+		insertKey(mapping, keys[0], value, index)
+
+		return
+	}
+
+	if existing == nil || existing.Kind != yaml.MappingNode {
+		existing = &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+		removeKey(mapping, keys[0])
+		insertKey(mapping, keys[0], existing, index)
+	}
+
+	setPath(existing, keys[1:], value, len(existing.Content))
+}
+
+func insertKey(mapping *yaml.Node, key string, value *yaml.Node, index int) {
+	if index > len(mapping.Content) {
+		index = len(mapping.Content)
+	}
+
+	content := append([]*yaml.Node{}, mapping.Content[:index]...)
+	content = append(content, scalarNode("!!str", key), value)
+	mapping.Content = append(content, mapping.Content[index:]...)
+}
+
+// setVersion updates the version, or adds it first, after $schema
+func setVersion(root *yaml.Node, version int) {
+	versionNode := scalarNode("!!int", strconv.Itoa(version))
+
+	if existing, _ := mappingValue(root, "version"); existing != nil {
+		*existing = *versionNode
+
+		return
+	}
+
+	index := 0
+	if schemaNode, schemaIndex := mappingValue(root, "$schema"); schemaNode != nil {
+		index = schemaIndex + 2
+	}
+
+	insertKey(root, "version", versionNode, index)
+
+	// Keep a comment at the top of the file above the new key
+	if index == 0 && len(root.Content) > 2 {
+		root.Content[0].HeadComment, root.Content[2].HeadComment = root.Content[2].HeadComment, ""
+	}
+}
+
+func scalarNode(tag string, value string) *yaml.Node {
+	return &yaml.Node{
+		Kind:  yaml.ScalarNode,
+		Tag:   tag,
+		Value: value,
+	}
+}
+
+// renderJSONNode writes the node as JSON, keeping objects and lists that were on a single line on a single line
+func renderJSONNode(node *yaml.Node, indent string, prefix string) string {
+	switch node.Kind {
+	case yaml.MappingNode, yaml.SequenceNode:
+		open, close := "{", "}"
+		if node.Kind == yaml.SequenceNode {
+			open, close = "[", "]"
+		}
+
+		if len(node.Content) == 0 {
+			return open + close
+		}
+
+		items := []string{}
+		singleLine := node.Line != 0
+		step := 1
+		if node.Kind == yaml.MappingNode {
+			step = 2
+		}
+
+		for i := 0; i < len(node.Content); i += step {
+			if node.Content[i].Line != node.Line {
+				singleLine = false
+			}
+
+			if node.Kind == yaml.SequenceNode {
+				items = append(items, renderJSONNode(node.Content[i], indent, prefix+indent))
+				continue
+			}
+
+			items = append(items, jsonString(node.Content[i].Value)+": "+renderJSONNode(node.Content[i+1], indent, prefix+indent))
+		}
+
+		if singleLine {
+			return open + strings.Join(items, ", ") + close
+		}
+
+		return open + "\n" + prefix + indent + strings.Join(items, ",\n"+prefix+indent) + "\n" + prefix + close
+	case yaml.AliasNode:
+		return renderJSONNode(node.Alias, indent, prefix)
+	}
+
+	switch node.Tag {
+	case "!!int", "!!float", "!!bool":
+		return node.Value
+	case "!!null":
+		return "null"
+	}
+
+	return jsonString(node.Value)
+}
+
+// jsonString quotes a string for JSON without escaping HTML characters
+func jsonString(value string) string {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	_ = encoder.Encode(value)
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
@@ -0,0 +1,336 @@
+package configuration
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvPrefix is the prefix of the environment variables that override config values,
+// nested keys are separated by a double underscore like PROJECTL_GENERATORS__ESLINT__ENABLED and
+// are lowercased, so mixed case keys like generators.prettier.options.printWidth need the --set flag
+const EnvPrefix = "PROJECTL_"
+
+// SourceDefault is the source of the values nothing has set
+const SourceDefault = "default"
+
+// Override is a config value set outside of the config files
+type Override struct {
+	// Path of the key, nested keys are separated by dots like generators.eslint.enabled
+	Path   string
+	Value  string
+	Source string
+	// SkipInvalid skips an override that doesn't fit the config with a warning instead of an error
+	SkipInvalid bool
+}
+
+// EnvOverrides gets the overrides from PROJECTL_* environment variables, other tools
+// use the prefix too, like PROJECTL_VERSION to pin projectl, so invalid ones are skipped
+func EnvOverrides(environ []string) []Override {
+	overrides := []Override{}
+
+	for _, variable := range environ {
+		parts := strings.SplitN(variable, "=", 2)
+		if len(parts) != 2 || !strings.HasPrefix(parts[0], EnvPrefix) {
+			continue
+		}
+
+		keys := strings.Split(strings.ToLower(strings.TrimPrefix(parts[0], EnvPrefix)), "__")
+		overrides = append(overrides, Override{
+			Path:        strings.Join(keys, "."),
+			Value:       parts[1],
+			Source:      "env " + parts[0],
+			SkipInvalid: true,
+		})
+	}
+
+	// os.Environ has no defined order
+	sort.Slice(overrides, func(i, j int) bool {
+		return overrides[i].Path < overrides[j].Path
+	})
+
+	return overrides
+}
+
+// ResolvedConfig is the effective config along with where each value came from
+type ResolvedConfig struct {
+	Config   *Config
+	Values   map[string]interface{}
+	Sources  map[string]string
+	Warnings []string
+}
+
+// ResolvedEntry is a single value of the effective config
+type ResolvedEntry struct {
+	Path   string
+	Value  interface{}
+	Source string
+}
+
+// ResolveConfig merges the config file over its base configs, and the overrides
+// over them in order, so later overrides like flags win over earlier ones like env,
+// an override replaces a list instead of appending to it
+func ResolveConfig(configFilePath string, overrides []Override) (*ResolvedConfig, error) {
+	layers := []configLayer{}
+
+	// A project without a config file gets the defaults
+	if _, err := os.Stat(configFilePath); err == nil {
+		fileLayers, err := loadLayers(configFilePath, nil)
+		if err != nil {
+			return nil, err
+		}
+		layers = append(layers, fileLayers...)
+	}
+
+	configType := reflect.TypeOf(Config{})
+	resolvedConfig := &ResolvedConfig{
+		Config:  &Config{},
+		Values:  map[string]interface{}{},
+		Sources: map[string]string{},
+	}
+
+	for _, override := range overrides {
+		layer, err := override.layer()
+		if err != nil {
+			if override.SkipInvalid {
+				resolvedConfig.Warnings = append(resolvedConfig.Warnings, fmt.Sprintf("%s: %s, skipped", override.Source, err.Error()))
+				continue
+			}
+
+			return nil, fmt.Errorf("%w: %s: %s", ErrInvalidConfigFile, override.Source, err.Error())
+		}
+		layers = append(layers, layer)
+	}
+
+	for _, layer := range layers {
+		for _, warning := range deprecationWarnings(configType, layer.values, "") {
+			resolvedConfig.Warnings = append(resolvedConfig.Warnings, layer.source+": "+warning)
+		}
+
+		moveDeprecatedValues(layer.values)
+		resolvedConfig.Values = mergeValues(configType, resolvedConfig.Values, layer.values, false, layer.replaceLists).(map[string]interface{})
+		recordSources(configType, layer.values, "", layer.source, false, layer.replaceLists, resolvedConfig.Sources)
+	}
+
+	mergedBytes, err := json.Marshal(resolvedConfig.Values)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := decodeStrict(mergedBytes, resolvedConfig.Config); err != nil {
+		return nil, fmt.Errorf("%w: %s: %s", ErrInvalidConfigFile, configFilePath, err.Error())
+	}
+
+	resolvedConfig.Config.applyDeprecatedKeys()
+
+	return resolvedConfig, nil
+}
+
+// Entries lists every value that is set, or has a default, sorted by path
+func (resolvedConfig *ResolvedConfig) Entries() []ResolvedEntry {
+	entries := map[string]ResolvedEntry{}
+
+	collectDefaults(NewSchema(), "", entries)
+	collectEntries(reflect.TypeOf(Config{}), resolvedConfig.Values, "", resolvedConfig.Sources, entries)
+
+	sortedEntries := []ResolvedEntry{}
+	for _, entry := range entries {
+		sortedEntries = append(sortedEntries, entry)
+	}
+
+	sort.Slice(sortedEntries, func(i, j int) bool {
+		return sortedEntries[i].Path < sortedEntries[j].Path
+	})
+
+	return sortedEntries
+}
+
+// layer converts the override to the nested values of a config file
+func (override Override) layer() (configLayer, error) {
+	keys := strings.Split(override.Path, ".")
+
+	goType, err := typeAtPath(reflect.TypeOf(Config{}), keys)
+	if err != nil {
+		return configLayer{}, err
+	}
+
+	value, err := parseOverrideValue(goType, override.Value)
+	if err != nil {
+		return configLayer{}, err
+	}
+
+	values := map[string]interface{}{}
+	current := values
+	for _, key := range keys[:len(keys)-1] {
+		next := map[string]interface{}{}
+		current[key] = next
+		current = next
+	}
+	current[keys[len(keys)-1]] = value
+
+	// Type check the override the same way as a config file
+	valueBytes, err := json.Marshal(values)
+	if err == nil {
+		err = decodeStrict(valueBytes, &Config{})
+	}
+	if err != nil {
+		return configLayer{}, err
+	}
+
+	return configLayer{
+		source:       override.Source,
+		values:       values,
+		replaceLists: true,
+	}, nil
+}
+
+// typeAtPath finds the type of the key, keys below a map are the map's own keys
+func typeAtPath(goType reflect.Type, keys []string) (reflect.Type, error) {
+	for i, key := range keys {
+		switch goType.Kind() {
+		case reflect.Struct:
+			field, ok := fieldByJSONName(goType, key)
+			if !ok {
+				return nil, fmt.Errorf("unknown key %q", strings.Join(keys[:i+1], "."))
+			}
+			goType = field.Type
+		case reflect.Map:
+			goType = goType.Elem()
+		default:
+			return nil, fmt.Errorf("key %q has no keys below it", strings.Join(keys[:i], "."))
+		}
+	}
+
+	return goType, nil
+}
+
+// parseOverrideValue converts the text of an override to the JSON value of its key,
+// lists can be JSON or comma separated and objects are JSON
+func parseOverrideValue(goType reflect.Type, text string) (interface{}, error) {
+	if goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+
+	switch goType.Kind() {
+	case reflect.String:
+		return text, nil
+	case reflect.Bool:
+		value, err := strconv.ParseBool(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a boolean", text)
+		}
+
+		return value, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		value, err := strconv.Atoi(text)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+
+		return value, nil
+	case reflect.Slice:
+		if !strings.HasPrefix(strings.TrimSpace(text), "[") {
+			values := []interface{}{}
+			for _, value := range strings.Split(text, ",") {
+				values = append(values, strings.TrimSpace(value))
+			}
+
+			return values, nil
+		}
+	case reflect.Interface:
+		// Free-form values are JSON when they parse as JSON, and a string otherwise
+		var value interface{}
+		if err := json.Unmarshal([]byte(text), &value); err != nil {
+			return text, nil
+		}
+
+		return value, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal([]byte(text), &value); err != nil {
+		return nil, fmt.Errorf("%w while parsing %q as JSON", err, text)
+	}
+
+	return value, nil
+}
+
+// recordSources notes the layer as the source of every value it sets,
+// appended lists keep the sources of the earlier layers
+func recordSources(goType reflect.Type, value interface{}, path string, source string, appendList bool, replaceLists bool, sources map[string]string) {
+	object, isObject := value.(map[string]interface{})
+
+	switch {
+	case goType.Kind() == reflect.Struct && isObject:
+		for key, fieldValue := range object {
+			field, ok := fieldByJSONName(goType, key)
+			if !ok {
+				continue
+			}
+
+			recordSources(field.Type, fieldValue, joinPath(path, key), source, field.Tag.Get("merge") == "append" && !replaceLists, replaceLists, sources)
+		}
+	case goType.Kind() == reflect.Map && isObject:
+		for key, elemValue := range object {
+			recordSources(goType.Elem(), elemValue, joinPath(path, key), source, false, replaceLists, sources)
+		}
+	case appendList && sources[path] != "":
+		sources[path] += ", " + source
+	default:
+		sources[path] = source
+	}
+}
+
+func collectEntries(goType reflect.Type, value interface{}, path string, sources map[string]string, entries map[string]ResolvedEntry) {
+	object, isObject := value.(map[string]interface{})
+
+	switch {
+	case goType.Kind() == reflect.Struct && isObject:
+		for key, fieldValue := range object {
+			if field, ok := fieldByJSONName(goType, key); ok {
+				collectEntries(field.Type, fieldValue, joinPath(path, key), sources, entries)
+			}
+		}
+	case goType.Kind() == reflect.Map && isObject:
+		for key, elemValue := range object {
+			collectEntries(goType.Elem(), elemValue, joinPath(path, key), sources, entries)
+		}
+	default:
+		entries[path] = ResolvedEntry{
+			Path:   path,
+			Value:  value,
+			Source: sources[path],
+		}
+	}
+}
+
+func collectDefaults(schema *Schema, path string, entries map[string]ResolvedEntry) {
+	for key, propertySchema := range schema.Properties {
+		// The loader moves deprecated keys, so their defaults show up under the new keys
+		if propertySchema.Deprecated {
+			continue
+		}
+
+		collectDefaults(propertySchema, joinPath(path, key), entries)
+	}
+
+	if schema.Default != nil {
+		entries[path] = ResolvedEntry{
+			Path:   path,
+			Value:  schema.Default,
+			Source: SourceDefault,
+		}
+	}
+}
+
+func joinPath(path string, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "." + key
+}
@@ -0,0 +1,210 @@
+package configuration
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// SchemaDraft is the JSON Schema version of the generated schema
+const SchemaDraft = "http://json-schema.org/draft-07/schema#"
+
+// Schema is a JSON Schema document, generated from the struct tags of Config
+type Schema struct {
+	Schema               string             `json:"$schema,omitempty"`
+	Title                string             `json:"title,omitempty"`
+	Description          string             `json:"description,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties interface{}        `json:"additionalProperties,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Enum                 []interface{}      `json:"enum,omitempty"`
+	Default              interface{}        `json:"default,omitempty"`
+	Minimum              *int               `json:"minimum,omitempty"`
+	Maximum              *int               `json:"maximum,omitempty"`
+	Deprecated           bool               `json:"deprecated,omitempty"`
+}
+
+// schemaProvider is implemented by config types that know their own schema
+type schemaProvider interface {
+	JSONSchema() *Schema
+}
+
+// NewSchema generates the JSON Schema of the config file
+func NewSchema() *Schema {
+	schema := schemaForType(reflect.TypeOf(Config{}))
+	schema.Schema = SchemaDraft
+	schema.Title = "projectl config"
+
+	return schema
+}
+
+func schemaForType(goType reflect.Type) *Schema {
+	if provider, ok := reflect.Zero(goType).Interface().(schemaProvider); ok {
+		return provider.JSONSchema()
+	}
+
+	switch goType.Kind() {
+	case reflect.Ptr:
+		// Pointers only tell an unset value apart from the zero value
+		return schemaForType(goType.Elem())
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return &Schema{Type: "integer"}
+	case reflect.Float32, reflect.Float64:
+		return &Schema{Type: "number"}
+	case reflect.Slice, reflect.Array:
+		return &Schema{Type: "array", Items: schemaForType(goType.Elem())}
+	case reflect.Map:
+		schema := &Schema{Type: "object"}
+
+		// Anything is allowed in a map of interface{}, so leave the values unconstrained
+		if goType.Elem().Kind() != reflect.Interface {
+			schema.AdditionalProperties = schemaForType(goType.Elem())
+		}
+
+		return schema
+	case reflect.Struct:
+		schema := &Schema{
+			Type:                 "object",
+			Properties:           map[string]*Schema{},
+			AdditionalProperties: false,
+		}
+
+		for i := 0; i < goType.NumField(); i++ {
+			field := goType.Field(i)
+			name := jsonName(field)
+			if name == "" {
+				continue
+			}
+
+			schema.Properties[name] = schemaForField(field)
+		}
+
+		return schema
+	}
+
+	// interface{} accepts any value
+	return &Schema{}
+}
+
+func schemaForField(field reflect.StructField) *Schema {
+	schema := schemaForType(field.Type)
+	schema.Description = field.Tag.Get("description")
+
+	if defaultValue, ok := field.Tag.Lookup("default"); ok {
+		schema.Default = typedTagValue(field.Type, defaultValue)
+	}
+
+	if enum, ok := field.Tag.Lookup("enum"); ok {
+		schema.Enum = []interface{}{}
+		for _, value := range strings.Split(enum, ",") {
+			schema.Enum = append(schema.Enum, typedTagValue(field.Type, value))
+		}
+	}
+
+	if minimum, err := strconv.Atoi(field.Tag.Get("minimum")); err == nil {
+		schema.Minimum = &minimum
+	}
+
+	if maximum, err := strconv.Atoi(field.Tag.Get("maximum")); err == nil {
+		schema.Maximum = &maximum
+	}
+
+	schema.Deprecated = field.Tag.Get("deprecated") != ""
+
+	return schema
+}
+
+// typedTagValue converts a tag value to the JSON type of the field
+func typedTagValue(goType reflect.Type, value string) interface{} {
+	if goType.Kind() == reflect.Ptr {
+		goType = goType.Elem()
+	}
+
+	switch goType.Kind() {
+	case reflect.Bool:
+		if boolValue, err := strconv.ParseBool(value); err == nil {
+			return boolValue
+		}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if intValue, err := strconv.Atoi(value); err == nil {
+			return intValue
+		}
+	}
+
+	return value
+}
+
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "-" {
+		return ""
+	}
+
+	return name
+}
+
+// validate checks the set values against the enums and ranges of the schema,
+// zero values are unset and left to the generators' defaults
+func (schema *Schema) validate(value reflect.Value, path string) []string {
+	problems := []string{}
+
+	if value.Kind() == reflect.Struct {
+		for i := 0; i < value.NumField(); i++ {
+			name := jsonName(value.Type().Field(i))
+			propertySchema, ok := schema.Properties[name]
+			if !ok {
+				continue
+			}
+
+			fieldPath := name
+			if path != "" {
+				fieldPath = path + "." + name
+			}
+
+			problems = append(problems, propertySchema.validate(value.Field(i), fieldPath)...)
+		}
+
+		return problems
+	}
+
+	if value.IsZero() {
+		return problems
+	}
+
+	if value.Kind() == reflect.Ptr {
+		return schema.validate(value.Elem(), path)
+	}
+
+	if len(schema.Enum) > 0 {
+		allowed := []string{}
+		found := false
+		for _, enumValue := range schema.Enum {
+			allowed = append(allowed, fmt.Sprint(enumValue))
+			if fmt.Sprint(enumValue) == fmt.Sprint(value.Interface()) {
+				found = true
+			}
+		}
+
+		if !found {
+			problems = append(problems, fmt.Sprintf("%s %v is not one of: %s", path, value.Interface(), strings.Join(allowed, ", ")))
+		}
+	}
+
+	if value.Kind() == reflect.Int {
+		if schema.Minimum != nil && value.Int() < int64(*schema.Minimum) {
+			problems = append(problems, fmt.Sprintf("%s %d is less than the minimum of %d", path, value.Int(), *schema.Minimum))
+		}
+
+		if schema.Maximum != nil && value.Int() > int64(*schema.Maximum) {
+			problems = append(problems, fmt.Sprintf("%s %d is greater than the maximum of %d", path, value.Int(), *schema.Maximum))
+		}
+	}
+
+	return problems
+}
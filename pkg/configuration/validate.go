@@ -0,0 +1,75 @@
+package configuration
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+// Errors
+var (
+	ErrInvalidConfig = errors.New("invalid config")
+)
+
+// ValidationError lists every problem found in a config that parsed fine
+type ValidationError struct {
+	Problems []string
+}
+
+func (validationError *ValidationError) Error() string {
+	return fmt.Sprintf("%s:\n  - %s", ErrInvalidConfig, strings.Join(validationError.Problems, "\n  - "))
+}
+
+// Unwrap allows errors.Is checks against ErrInvalidConfig
+func (validationError *ValidationError) Unwrap() error {
+	return ErrInvalidConfig
+}
+
+// Validate checks the values of the config against each other and the detected project
+func (config *Config) Validate(service *projector.Service) error {
+	// Enums and ranges come from the same schema that is published for editors
+	problems := NewSchema().validate(reflect.ValueOf(*config), "")
+
+	if config.Version > CurrentVersion {
+		problems = append(problems, fmt.Sprintf("version %d is newer than the version %d this projectl supports, update projectl", config.Version, CurrentVersion))
+	}
+
+	if config.Generators.Makefile.GoHTTP && config.DockerPort == 0 {
+		problems = append(problems, "generators.makefile.go_http requires docker_port to be set")
+	}
+
+	if config.DockerTarget != "" {
+		targets := []string{}
+		for _, target := range service.Go.Targets {
+			targets = append(targets, target)
+		}
+		sort.Strings(targets)
+
+		found := false
+		for _, target := range targets {
+			if target == config.DockerTarget {
+				found = true
+			}
+		}
+
+		if !found {
+			if len(targets) == 0 {
+				problems = append(problems, fmt.Sprintf("docker_target %q is set but no Go targets were detected", config.DockerTarget))
+			} else {
+				problems = append(problems, fmt.Sprintf("docker_target %q does not match a Go target, expected one of: %s", config.DockerTarget, strings.Join(targets, ", ")))
+			}
+		}
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{
+			Problems: problems,
+		}
+	}
+
+	return nil
+}
@@ -3,7 +3,9 @@ package generators
 import (
 	"fmt"
 	"os"
+	"strings"
 
+	"github.com/aaronellington/projectl/pkg/language"
 	"github.com/aaronellington/projectl/pkg/projector"
 )
 
@@ -11,23 +13,29 @@ import (
 type Dockerfile struct {
 	Port   int
 	Target string
-	Custom bool
 }
 
 // Generate the config file
 func (dockerfile *Dockerfile) Generate(service *projector.Service) error {
-	if dockerfile.Custom {
-		return nil
-	}
-
 	file, err := os.Create("Dockerfile")
 	if err != nil {
 		return err
 	}
 
 	if service.Npm.Enabled {
-		_, _ = file.WriteString(`FROM node:16-buster as nodeBuilder
-WORKDIR /build-staging
+		// Distro tags like -buster only exist for the Node versions released while the distro was current
+		_, _ = file.WriteString("FROM node:" + service.Npm.Version() + " as nodeBuilder\n")
+
+		switch service.Npm.PackageManager {
+		case language.PackageManagerYarn, language.PackageManagerPnpm:
+			_, _ = file.WriteString("RUN corepack enable\n")
+		case language.PackageManagerBun:
+			_, _ = file.WriteString("RUN npm install -g bun\n")
+		}
+
+		// Workspaces share the root lock file and node_modules, so the whole tree is copied,
+		// installing from the package.json files first would be undone by make clean-full
+		_, _ = file.WriteString(`WORKDIR /build-staging
 COPY . .
 RUN make clean-full
 RUN make lint-npm test-npm build-npm
@@ -39,6 +47,8 @@ RUN make lint-npm test-npm build-npm
 		dockerfile.modeGo(service, file)
 	} else if service.PHP.Enabled {
 		dockerfile.modePHP(service, file)
+	} else if service.Ruby.Enabled {
+		dockerfile.modeRuby(service, file)
 	} else if service.Npm.Enabled {
 		dockerfile.modeNPM(service, file)
 	}
@@ -83,7 +93,12 @@ CMD ["./` + targetBin + `"]
 }
 
 func (dockerfile *Dockerfile) modePHP(service *projector.Service, file *os.File) {
-	_, _ = file.WriteString(`FROM aaronellington/php-fpm-webserver:latest
+	imageTag := "latest"
+	if service.PHP.Version() != "" {
+		imageTag = service.PHP.Version()
+	}
+
+	_, _ = file.WriteString(`FROM aaronellington/php-fpm-webserver:` + imageTag + `
 COPY . .
 RUN make clean-full
 `)
@@ -92,13 +107,51 @@ RUN make clean-full
 		_, _ = file.WriteString("COPY --from=nodeBuilder /build-staging/public/build/ ./public/build/\n")
 	}
 
-	_, _ = file.WriteString(`RUN make lint-php test-php build-php-prod
-RUN mkdir var
+	_, _ = file.WriteString("RUN make lint-php test-php build-php-prod\n")
+
+	framework := service.PHP.Framework()
+	switch {
+	case framework.Name == language.FrameworkSymfony && framework.MajorVersion >= 4:
+		_, _ = file.WriteString(`RUN APP_ENV=prod bin/console cache:warmup
+RUN chown -R www-data:www-data var
+`)
+	case framework.Name == language.FrameworkLaravel:
+		_, _ = file.WriteString(`RUN php artisan view:cache
+RUN chown -R www-data:www-data storage bootstrap/cache
+`)
+	default:
+		_, _ = file.WriteString(`RUN mkdir var
 RUN chown www-data:www-data var
+`)
+	}
+}
+
+func (dockerfile *Dockerfile) modeRuby(service *projector.Service, file *os.File) {
+	_, _ = file.WriteString(`FROM ruby:` + service.Ruby.Version() + `-buster
+WORKDIR /app
+COPY . .
+RUN make clean-full
+`)
+
+	if service.Npm.Enabled && service.Ruby.IsRails() {
+		_, _ = file.WriteString("COPY --from=nodeBuilder /build-staging/app/assets/builds/ ./app/assets/builds/\n")
+	}
+
+	_, _ = file.WriteString(`RUN make lint-ruby test-ruby build-ruby
+`)
+
+	if service.Ruby.IsRails() {
+		_, _ = file.WriteString(`RUN RAILS_ENV=production SECRET_KEY_BASE=precompile bundle exec rails assets:precompile
+CMD ["bundle", "exec", "rails", "server", "-b", "0.0.0.0"]
+`)
+		return
+	}
+
+	_, _ = file.WriteString(`CMD ["bundle", "exec", "rackup", "--host", "0.0.0.0"]
 `)
 }
 
 func (dockerfile *Dockerfile) modeNPM(service *projector.Service, file *os.File) {
-	_, _ = file.WriteString(`CMD ["npm", "run", "start"]
+	_, _ = file.WriteString(`CMD ["` + strings.Join(service.Npm.StartCommand(), `", "`) + `"]
 `)
 }
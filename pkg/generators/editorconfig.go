@@ -0,0 +1,128 @@
+package generators
+
+import (
+	"sort"
+	"text/template"
+
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+const editorconfigTemplate = `root = true
+{{ range .Sections }}
+[{{ .Glob }}]{{ range .Properties }}
+{{ .Name }} = {{ .Value }}{{ end }}
+{{ end }}
+`
+
+// TemplatePayloadEditorconfig template payload
+type TemplatePayloadEditorconfig struct {
+	Sections []TemplateEditorconfigSection
+}
+
+// TemplateEditorconfigSection is a section of .editorconfig
+type TemplateEditorconfigSection struct {
+	Glob       string
+	Properties []TemplateEditorconfigProperty
+}
+
+// TemplateEditorconfigProperty is a property of an .editorconfig section
+type TemplateEditorconfigProperty struct {
+	Name  string
+	Value string
+}
+
+// EditorconfigGenerator generates the .editorconfig file
+type EditorconfigGenerator struct {
+	Config map[string]map[string]string
+}
+
+// editorconfigDependencies are the tools whose style the .editorconfig carries over to editors
+var editorconfigDependencies = []string{"eslint", "prettier", "stylelint"}
+
+// frontendGlob matches the files the npm tools lint and format
+const frontendGlob = "*.{cjs,css,html,js,json,jsx,less,mjs,scss,svelte,ts,tsx,vue}"
+
+// Generate the config
+func (p EditorconfigGenerator) Generate(service *projector.Service) error {
+	if !service.Npm.Enabled || !hasAnyDirectDependency(service, editorconfigDependencies) {
+		return nil
+	}
+
+	// Tabs like the EslintGenerator, only for the frontend files so the PHP and Ruby
+	// files keep the spaces their own linters require
+	globs := []string{"*", frontendGlob, "Makefile", "*.{yml,yaml}", "*.md"}
+	sections := map[string]map[string]string{
+		"*": {
+			"charset":                  "utf-8",
+			"end_of_line":              "lf",
+			"insert_final_newline":     "true",
+			"trim_trailing_whitespace": "true",
+		},
+		frontendGlob: {
+			"indent_style": "tab",
+		},
+		"Makefile": {
+			"indent_style": "tab",
+		},
+		"*.{yml,yaml}": {
+			"indent_size":  "2",
+			"indent_style": "space",
+		},
+		"*.md": {
+			"trim_trailing_whitespace": "false",
+		},
+	}
+
+	projectGlobs := []string{}
+	for glob, properties := range p.Config {
+		if _, found := sections[glob]; !found {
+			sections[glob] = map[string]string{}
+			projectGlobs = append(projectGlobs, glob)
+		}
+
+		for name, value := range properties {
+			sections[glob][name] = value
+		}
+	}
+	sort.Strings(projectGlobs)
+
+	payload := TemplatePayloadEditorconfig{}
+	for _, glob := range append(globs, projectGlobs...) {
+		section := TemplateEditorconfigSection{
+			Glob: glob,
+		}
+
+		names := []string{}
+		for name := range sections[glob] {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			section.Properties = append(section.Properties, TemplateEditorconfigProperty{
+				Name:  name,
+				Value: sections[glob][name],
+			})
+		}
+
+		payload.Sections = append(payload.Sections, section)
+	}
+
+	editorconfig := &projector.GeneratorTemplated{
+		TargetFile: ".editorconfig",
+		Template:   template.Must(template.New("editorconfig").Parse(editorconfigTemplate)),
+		Payload:    payload,
+	}
+
+	return editorconfig.Generate(service)
+}
+
+func hasAnyDirectDependency(service *projector.Service, dependencies []string) bool {
+	for _, dependency := range dependencies {
+		if service.Npm.HasDirectDependency(dependency) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,40 @@
+package generators
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"os"
+)
+
+// generatedMarker is written at the top of the generated files that can be
+// replaced by a file for another tool version, so they can be told apart from
+// files written by hand
+const generatedMarker = "Generated by projectl"
+
+// removeStaleFile removes a config file that was generated before the project
+// switched tool versions, a file that wasn't generated is left alone
+func removeStaleFile(fileName string, isGenerated func(fileBytes []byte) bool) error {
+	fileBytes, err := ioutil.ReadFile(fileName)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("%w while reading file %s", err, fileName)
+	}
+
+	if !isGenerated(fileBytes) {
+		return nil
+	}
+
+	if err := os.Remove(fileName); err != nil {
+		return fmt.Errorf("%w while removing file %s", err, fileName)
+	}
+
+	return nil
+}
+
+func hasGeneratedMarker(fileBytes []byte) bool {
+	return bytes.Contains(fileBytes, []byte(generatedMarker))
+}
@@ -3,6 +3,7 @@ package generators
 import (
 	"os"
 
+	"github.com/aaronellington/projectl/pkg/language"
 	"github.com/aaronellington/projectl/pkg/projector"
 )
 
@@ -41,6 +42,12 @@ jobs:
     steps:
 `)
 
+	// The setup steps look for lock files to key their caches on, so the code is checked out first
+	_, _ = workflowFile.WriteString(`
+      - name: Check out code
+        uses: actions/checkout@v2
+`)
+
 	_, _ = workflowFile.WriteString(`
       - name: Set up Go
         uses: actions/setup-go@v1
@@ -49,28 +56,33 @@ jobs:
 `)
 
 	if service.Npm.Enabled {
-		_, _ = workflowFile.WriteString(`
-      - name: Set up Node
-        uses: actions/setup-node@v1
-        with:
-          node-version: 16
-`)
+		githubWorkflow.writeNode(service, workflowFile)
 	}
 
 	if service.PHP.Enabled {
+		phpVersion := "8.0"
+		if service.PHP.Version() != "" {
+			phpVersion = service.PHP.Version()
+		}
+
 		_, _ = workflowFile.WriteString(`
       - name: Set up PHP
         uses: shivammathur/setup-php@v2
         with:
-          php-version: '8.0'
+          php-version: '` + phpVersion + `'
           tools: composer:v2
 `)
 	}
 
-	_, _ = workflowFile.WriteString(`
-      - name: Check out code
-        uses: actions/checkout@v2
+	if service.Ruby.Enabled {
+		_, _ = workflowFile.WriteString(`
+      - name: Set up Ruby
+        uses: ruby/setup-ruby@v1
+        with:
+          ruby-version: '` + service.Ruby.Version() + `'
 `)
+	}
+
 	_, _ = workflowFile.WriteString(`
       - name: Build
         run: make full projectl git-change-check
@@ -78,3 +90,40 @@ jobs:
 
 	return nil
 }
+
+func (githubWorkflow *GithubWorkflow) writeNode(service *projector.Service, workflowFile *os.File) {
+	// setup-node needs pnpm available before it can resolve the pnpm cache
+	if service.Npm.PackageManager == language.PackageManagerPnpm {
+		pnpmVersion := service.Npm.PackageManagerVersion()
+		if pnpmVersion == "" {
+			pnpmVersion = "latest"
+		}
+
+		_, _ = workflowFile.WriteString(`
+      - name: Set up pnpm
+        uses: pnpm/action-setup@v2
+        with:
+          version: ` + pnpmVersion + `
+`)
+	}
+
+	_, _ = workflowFile.WriteString(`
+      - name: Set up Node
+        uses: actions/setup-node@v2
+        with:
+          node-version: '` + service.Npm.Version() + `'
+`)
+
+	// setup-node can only cache when there is a lock file to key on
+	if service.Npm.LockFile != "" && service.Npm.PackageManager != language.PackageManagerBun {
+		_, _ = workflowFile.WriteString(`          cache: ` + service.Npm.PackageManager + `
+`)
+	}
+
+	if service.Npm.PackageManager == language.PackageManagerBun {
+		_, _ = workflowFile.WriteString(`
+      - name: Set up Bun
+        uses: oven-sh/setup-bun@v1
+`)
+	}
+}
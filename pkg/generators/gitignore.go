@@ -1,10 +1,10 @@
 package generators
 
 import (
-	"os"
 	"text/template"
 
 	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/language"
 	"github.com/aaronellington/projectl/pkg/projector"
 )
 
@@ -39,11 +39,7 @@ func NewGitignore(service *projector.Service, config *configuration.Config) *pro
 func getGitignorePayload(service *projector.Service, config *configuration.Config) TemplatePayloadGitignore {
 	distedFiles := []string{}
 	for _, distedFile := range service.DistedFiles {
-		if _, err := os.Open("." + distedFile + ".dist"); err != nil {
-			continue
-		}
-
-		distedFiles = append(distedFiles, distedFile)
+		distedFiles = append(distedFiles, distedFile.Path)
 	}
 
 	payload := TemplatePayloadGitignore{
@@ -78,14 +74,46 @@ func getGitignorePayload(service *projector.Service, config *configuration.Confi
 
 	// Add PHP Values
 	if service.PHP.Enabled {
+		phpValues := []string{
+			"/vendor/",
+			".phpunit.result.cache",
+			".php_cs.cache",
+			".php-cs-fixer.cache",
+			"/.php-cs-fixer.php",
+			".phpcs-cache",
+			"/phpstan.neon",
+			"/phpunit.xml",
+		}
+
+		framework := service.PHP.Framework()
+		if framework.Name == language.FrameworkSymfony && framework.MajorVersion >= 4 {
+			phpValues = append(phpValues, "/.env.local.php", "/public/bundles/")
+		}
+		if framework.Name == language.FrameworkLaravel {
+			phpValues = append(phpValues, "/public/hot", "/public/storage", "/storage/*.key")
+		}
+
 		payload.Sections = append(payload.Sections, TemplateGitignoreSection{
-			Name: "PHP Files",
-			Values: []string{
-				"/vendor/",
-				".phpunit.result.cache",
-				".php_cs.cache",
-				".phpcs-cache",
-			},
+			Name:   "PHP Files",
+			Values: phpValues,
+		})
+	}
+
+	// Add Ruby Values
+	if service.Ruby.Enabled {
+		rubyValues := []string{
+			"/vendor/bundle/",
+			"/tmp/",
+			"/.bundle/",
+		}
+
+		if service.Ruby.IsRails() {
+			rubyValues = append(rubyValues, "/log/", "/storage/", "/public/assets/")
+		}
+
+		payload.Sections = append(payload.Sections, TemplateGitignoreSection{
+			Name:   "Ruby Files",
+			Values: rubyValues,
 		})
 	}
 
@@ -96,12 +124,34 @@ func getGitignorePayload(service *projector.Service, config *configuration.Confi
 			"npm-debug.log",
 		}
 
+		// Every workspace package gets its own node_modules
+		if service.Npm.IsWorkspace() {
+			npmValues[0] = "node_modules/"
+		}
+
+		switch service.Npm.MonorepoTool {
+		case language.MonorepoToolTurbo:
+			npmValues = append(npmValues, ".turbo/")
+		case language.MonorepoToolNx:
+			npmValues = append(npmValues, "/.nx/cache/", "/.nx/workspace-data/")
+		}
+
+		switch service.Npm.PackageManager {
+		case language.PackageManagerYarn:
+			npmValues = append(npmValues, "yarn-error.log", "/.yarn/cache/", "/.pnp.*")
+		case language.PackageManagerPnpm:
+			npmValues = append(npmValues, "pnpm-debug.log")
+		}
+
 		if service.Go.Enabled {
 			npmValues = append(npmValues, "/resources/dist/")
 		}
 		if service.PHP.Enabled {
 			npmValues = append(npmValues, "/public/build/")
 		}
+		if service.Ruby.Enabled && service.Ruby.IsRails() {
+			npmValues = append(npmValues, "/app/assets/builds/")
+		}
 
 		payload.Sections = append(payload.Sections, TemplateGitignoreSection{
 			Name:   "NPM Files",
@@ -6,13 +6,18 @@ import (
 	"io/ioutil"
 	"log"
 	"os"
+	"regexp"
 	"strings"
 	"text/template"
 
 	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/language"
 	"github.com/aaronellington/projectl/pkg/projector"
 )
 
+// invalidTargetCharacters are the characters not allowed in the generated target names
+var invalidTargetCharacters = regexp.MustCompile(`[^A-Za-z0-9._-]`)
+
 const makefileTemplate = `.PHONY:{{ range .Targets }} {{ .Name }}{{ end }}
 
 SHELL=/bin/bash -o pipefail
@@ -62,6 +67,10 @@ func getMakefilePayload(service *projector.Service, config *configuration.Config
 		payload.Variables["PATH"] = "$(GO_PATH)/bin:$(PATH)"
 	}
 
+	if service.PHP.Enabled {
+		payload.Variables["PHP_BIN_DIR"] = service.PHP.BinDir()
+	}
+
 	addHelpTarget(service, payload)
 	addFullTargets(service, payload)
 	addDockerTargets(service, config, payload)
@@ -69,6 +78,8 @@ func getMakefilePayload(service *projector.Service, config *configuration.Config
 	addLintTargets(service, payload)
 	addTestTargets(service, payload)
 	addWatchTargets(service, config, payload)
+	addPHPFrameworkTargets(service, payload)
+	addComposerScriptTargets(service, payload)
 	addCleanTargets(service, payload)
 	addCopyConfigTarget(service, payload)
 	addPipelineTargets(service, payload)
@@ -109,6 +120,17 @@ func addFullTargets(service *projector.Service, payload *TemplatePayloadMakefile
 		})
 	}
 
+	if service.Ruby.Enabled {
+		payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
+			Name: "full-ruby",
+			PreTargets: []string{
+				"lint-ruby",
+				"test-ruby",
+				"build-ruby",
+			},
+		})
+	}
+
 	if service.Npm.Enabled {
 		payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
 			Name: "full-npm",
@@ -157,11 +179,7 @@ func addPipelineTargets(service *projector.Service, payload *TemplatePayloadMake
 func addCopyConfigTarget(service *projector.Service, payload *TemplatePayloadMakefile) {
 	commands := []string{}
 	for _, distedFile := range service.DistedFiles {
-		if _, err := os.Open("." + distedFile + ".dist"); err != nil {
-			continue
-		}
-
-		commands = append(commands, fmt.Sprintf("[ -f %s ] || cp %s.dist %s", distedFile, distedFile, distedFile))
+		commands = append(commands, fmt.Sprintf("[ -f %s ] || cp %s %s", distedFile.LocalPath(), distedFile.LocalTemplate(), distedFile.LocalPath()))
 	}
 
 	targetCopyConfig := &TemplateMakefileTarget{
@@ -189,11 +207,7 @@ func addHelpTarget(service *projector.Service, payload *TemplatePayloadMakefile)
 func addCleanTargets(service *projector.Service, payload *TemplatePayloadMakefile) {
 	cleanArguments := []string{}
 	for _, distedFile := range service.DistedFiles {
-		if _, err := os.Open("." + distedFile + ".dist"); err != nil {
-			continue
-		}
-
-		cleanArguments = append(cleanArguments, fmt.Sprintf(" --exclude='!%s'", distedFile))
+		cleanArguments = append(cleanArguments, fmt.Sprintf(" --exclude='!%s'", distedFile.Path))
 	}
 
 	targetClean := &TemplateMakefileTarget{
@@ -227,8 +241,8 @@ func addBuildTargets(service *projector.Service, payload *TemplatePayloadMakefil
 		targetBuildNpm := &TemplateMakefileTarget{
 			Name: "build-npm",
 			Commands: []string{
-				"npm install --no-save",
-				"npm run build",
+				service.Npm.InstallCommand(),
+				service.Npm.RunAllCommand("build"),
 			},
 		}
 		targetBuild.PreTargets = append(targetBuild.PreTargets, targetBuildNpm.Name)
@@ -243,6 +257,12 @@ func addBuildTargets(service *projector.Service, payload *TemplatePayloadMakefil
 				"composer install --no-dev --optimize-autoloader --classmap-authoritative --no-progress --no-interaction",
 			},
 		}
+		framework := service.PHP.Framework()
+		if framework.Name == language.FrameworkSymfony && framework.MajorVersion >= 4 {
+			targetBuildPHPProd.Commands = []string{
+				"APP_ENV=prod composer install --no-dev --optimize-autoloader --classmap-authoritative --no-progress --no-interaction",
+			}
+		}
 		if service.PHP.IsSymfony3() {
 			targetBuildPHPProd.Commands = []string{
 				"SYMFONY_ENV=prod composer install --no-dev --optimize-autoloader --classmap-authoritative --no-progress --no-interaction",
@@ -261,6 +281,18 @@ func addBuildTargets(service *projector.Service, payload *TemplatePayloadMakefil
 		payload.Targets = append(payload.Targets, targetBuildPHPTest)
 	}
 
+	if service.Ruby.Enabled {
+		targetBuildRuby := &TemplateMakefileTarget{
+			Name: "build-ruby",
+			Commands: []string{
+				"bundle config set --local path vendor/bundle",
+				"bundle install --jobs 4 --retry 3",
+			},
+		}
+		targetBuild.PreTargets = append(targetBuild.PreTargets, targetBuildRuby.Name)
+		payload.Targets = append(payload.Targets, targetBuildRuby)
+	}
+
 	if service.Go.Enabled {
 		buildCommands := []string{
 			"@go generate",
@@ -293,8 +325,8 @@ func addTestTargets(service *projector.Service, payload *TemplatePayloadMakefile
 		targetTestNpm := &TemplateMakefileTarget{
 			Name: "test-npm",
 			Commands: []string{
-				"npm install --no-save",
-				"npm run test",
+				service.Npm.InstallCommand(),
+				service.Npm.RunAllCommand("test"),
 			},
 		}
 		targetTest.PreTargets = append(targetTest.PreTargets, targetTestNpm.Name)
@@ -306,13 +338,32 @@ func addTestTargets(service *projector.Service, payload *TemplatePayloadMakefile
 			Name:       "test-php",
 			PreTargets: []string{"build-php-test"},
 			Commands: []string{
-				"$(shell composer config bin-dir)/phpunit src",
+				"$(PHP_BIN_DIR)/phpunit",
 			},
 		}
 		targetTest.PreTargets = append(targetTest.PreTargets, targetTestPHP.Name)
 		payload.Targets = append(payload.Targets, targetTestPHP)
 	}
 
+	if service.Ruby.Enabled {
+		testCommand := "bundle exec rake test"
+		if service.Ruby.TestFramework() == "rspec" {
+			testCommand = "bundle exec rspec"
+		} else if service.Ruby.IsRails() {
+			testCommand = "bundle exec rails test"
+		}
+
+		targetTestRuby := &TemplateMakefileTarget{
+			Name:       "test-ruby",
+			PreTargets: []string{"build-ruby"},
+			Commands: []string{
+				testCommand,
+			},
+		}
+		targetTest.PreTargets = append(targetTest.PreTargets, targetTestRuby.Name)
+		payload.Targets = append(payload.Targets, targetTestRuby)
+	}
+
 	if service.Go.Enabled {
 		targetTestGo := &TemplateMakefileTarget{
 			Name: "test-go",
@@ -333,13 +384,13 @@ func addWatchTargets(service *projector.Service, config *configuration.Config, p
 			Name: "watch-npm",
 			Commands: []string{
 				"clear",
-				"npm run watch",
+				service.Npm.RunCommand("watch"),
 			},
 		}
 		payload.Targets = append(payload.Targets, targetTestNpm)
 	}
 
-	if service.Go.Enabled && config.GoHTTP {
+	if service.Go.Enabled && config.Generators.Makefile.GoHTTP {
 		// TODO: does not support multiple go targets
 		targetTestGo := &TemplateMakefileTarget{
 			Name: "watch-go",
@@ -353,6 +404,81 @@ func addWatchTargets(service *projector.Service, config *configuration.Config, p
 	}
 }
 
+func addPHPFrameworkTargets(service *projector.Service, payload *TemplatePayloadMakefile) {
+	if !service.PHP.Enabled {
+		return
+	}
+
+	framework := service.PHP.Framework()
+
+	if framework.Name == language.FrameworkSymfony && framework.MajorVersion >= 4 {
+		payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
+			Name:    "console-cache-warmup",
+			Comment: "Clear and warm up the Symfony cache",
+			Commands: []string{
+				"bin/console cache:clear --no-warmup",
+				"bin/console cache:warmup",
+			},
+		})
+
+		if service.PHP.Requires("doctrine/doctrine-migrations-bundle") {
+			payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
+				Name:    "console-migrate",
+				Comment: "Run the database migrations",
+				Commands: []string{
+					"bin/console doctrine:migrations:migrate --no-interaction",
+				},
+			})
+		}
+	}
+
+	if framework.Name == language.FrameworkLaravel {
+		payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
+			Name:    "artisan-optimize",
+			Comment: "Cache the Laravel config, routes and views",
+			Commands: []string{
+				"php artisan optimize",
+			},
+		})
+
+		payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
+			Name:    "artisan-migrate",
+			Comment: "Run the database migrations",
+			Commands: []string{
+				"php artisan migrate --force",
+			},
+		})
+	}
+}
+
+func addComposerScriptTargets(service *projector.Service, payload *TemplatePayloadMakefile) {
+	if !service.PHP.Enabled {
+		return
+	}
+
+	targetNames := map[string]bool{}
+	for _, scriptName := range service.PHP.Scripts() {
+		// Script names like test:unit can't be used as is, make reads the colon as a rule
+		targetName := "composer-" + makeTargetName(scriptName)
+		if targetNames[targetName] {
+			continue
+		}
+		targetNames[targetName] = true
+
+		payload.Targets = append(payload.Targets, &TemplateMakefileTarget{
+			Name: targetName,
+			Commands: []string{
+				"composer run-script " + scriptName,
+			},
+		})
+	}
+}
+
+// makeTargetName replaces the characters make doesn't allow in target names with a dash
+func makeTargetName(name string) string {
+	return invalidTargetCharacters.ReplaceAllString(name, "-")
+}
+
 func addLintTargets(service *projector.Service, payload *TemplatePayloadMakefile) {
 	targetLint := &TemplateMakefileTarget{
 		Name:    "lint",
@@ -364,12 +490,24 @@ func addLintTargets(service *projector.Service, payload *TemplatePayloadMakefile
 		targetLanguage := &TemplateMakefileTarget{
 			Name: "lint-npm",
 			Commands: []string{
-				"npm install --no-save",
-				"npm run lint",
+				service.Npm.InstallCommand(),
+				service.Npm.RunAllCommand("lint"),
 			},
 		}
 		targetLint.PreTargets = append(targetLint.PreTargets, targetLanguage.Name)
 		payload.Targets = append(payload.Targets, targetLanguage)
+
+		if service.Npm.HasDirectDependency("typescript") {
+			targetTypecheck := &TemplateMakefileTarget{
+				Name: "typecheck-npm",
+				Commands: []string{
+					service.Npm.InstallCommand(),
+					service.Npm.ExecCommand("tsc --noEmit"),
+				},
+			}
+			targetLint.PreTargets = append(targetLint.PreTargets, targetTypecheck.Name)
+			payload.Targets = append(payload.Targets, targetTypecheck)
+		}
 	}
 
 	if service.PHP.Enabled {
@@ -377,15 +515,27 @@ func addLintTargets(service *projector.Service, payload *TemplatePayloadMakefile
 			Name:       "lint-php",
 			PreTargets: []string{"build-php-test"},
 			Commands: []string{
-				"$(shell composer config bin-dir)/php-cs-fixer fix",
-				"$(shell composer config bin-dir)/phpcs",
-				"$(shell composer config bin-dir)/phpstan analyse src --level=max",
+				"$(PHP_BIN_DIR)/php-cs-fixer fix",
+				"$(PHP_BIN_DIR)/phpcs",
+				"$(PHP_BIN_DIR)/phpstan analyse",
 			},
 		}
 		targetLint.PreTargets = append(targetLint.PreTargets, targetLintPHP.Name)
 		payload.Targets = append(payload.Targets, targetLintPHP)
 	}
 
+	if service.Ruby.Enabled {
+		targetLintRuby := &TemplateMakefileTarget{
+			Name:       "lint-ruby",
+			PreTargets: []string{"build-ruby"},
+			Commands: []string{
+				"bundle exec rubocop",
+			},
+		}
+		targetLint.PreTargets = append(targetLint.PreTargets, targetLintRuby.Name)
+		payload.Targets = append(payload.Targets, targetLintRuby)
+	}
+
 	if service.Go.Enabled {
 		targetLintGo := &TemplateMakefileTarget{
 			Name: "lint-go",
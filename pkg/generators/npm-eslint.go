@@ -1,21 +1,70 @@
 package generators
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"sort"
+	"strings"
+	"text/template"
 
+	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/language"
 	"github.com/aaronellington/projectl/pkg/projector"
+
+	// For embed
+	_ "embed"
+)
+
+// Errors
+var (
+	ErrMissingDependencies = errors.New("missing npm dependencies")
 )
 
+//go:embed npm/eslint.config.mjs
+var eslintFlatConfigTemplate string
+
+// eslintFlatConfigDependencies are imported by the generated eslint.config.mjs, pnpm only
+// resolves them when the project lists them itself
+var eslintFlatConfigDependencies = []string{"@eslint/eslintrc", "@eslint/js", "globals"}
+
+// vitestGlobals are the globals vitest injects when globals are enabled
+var vitestGlobals = map[string]string{
+	"afterAll":   "readonly",
+	"afterEach":  "readonly",
+	"beforeAll":  "readonly",
+	"beforeEach": "readonly",
+	"describe":   "readonly",
+	"expect":     "readonly",
+	"it":         "readonly",
+	"test":       "readonly",
+	"vi":         "readonly",
+}
+
 // EslintConfig is the file format for the eslintrc.json
 type EslintConfig struct {
-	Env     map[string]bool     `json:"env"`
-	Extends []string            `json:"extends"`
-	Rules   map[string][]string `json:"rules"`
+	Env            map[string]bool          `json:"env"`
+	Extends        []string                 `json:"extends"`
+	Rules          map[string]interface{}   `json:"rules"`
+	Settings       map[string]interface{}   `json:"settings,omitempty"`
+	IgnorePatterns []string                 `json:"ignorePatterns,omitempty"`
+	Overrides      []map[string]interface{} `json:"overrides,omitempty"`
 }
 
-// EslintGenerator generates the .php_cs config file
-type EslintGenerator struct{}
+// TemplatePayloadEslintFlatConfig template payload
+type TemplatePayloadEslintFlatConfig struct {
+	Extends []string
+	Globals []string
+	Rules   string
+	Legacy  string
+}
+
+// EslintGenerator generates the .eslintrc.json config file, or eslint.config.mjs for ESLint 9+
+type EslintGenerator struct {
+	Config configuration.EslintConfig
+}
 
 // Generate the config
 func (p EslintGenerator) Generate(service *projector.Service) error {
@@ -31,11 +80,12 @@ func (p EslintGenerator) Generate(service *projector.Service) error {
 		Extends: []string{
 			"eslint:recommended",
 		},
-		Rules: map[string][]string{
-			"comma-dangle": {"error", "always-multiline"},
-			"indent":       {"error", "tab"},
-			"quotes":       {"error", "single"},
-			"semi":         {"error", "always"},
+		Settings: map[string]interface{}{},
+		Rules: map[string]interface{}{
+			"comma-dangle": []string{"error", "always-multiline"},
+			"indent":       []string{"error", "tab"},
+			"quotes":       []string{"error", "single"},
+			"semi":         []string{"error", "always"},
 		},
 	}
 
@@ -55,9 +105,216 @@ func (p EslintGenerator) Generate(service *projector.Service) error {
 		config.Extends = append(config.Extends, "@vue/eslint-config-typescript/recommended")
 	}
 
+	// The next presets already include the react plugins, frameworks and test runners are only
+	// detected from package.json as the lock file also lists the dependencies of dependencies
+	if service.Npm.HasDirectDependency("react") && !service.Npm.HasDependency("next") {
+		config.Extends = append(config.Extends, "plugin:react/recommended")
+		config.Extends = append(config.Extends, "plugin:react-hooks/recommended")
+		config.Settings["react"] = map[string]string{"version": "detect"}
+
+		// The JSX transform from React 17 doesn't need React in scope
+		if service.Npm.DependencyMajorVersion("react") >= 17 {
+			config.Rules["react/react-in-jsx-scope"] = []string{"off"}
+		}
+	}
+
+	if service.Npm.HasDirectDependency("svelte") {
+		config.Extends = append(config.Extends, "plugin:svelte/recommended")
+	}
+
+	if service.Npm.HasDirectDependency("@angular/core") {
+		config.Extends = append(config.Extends, "plugin:@angular-eslint/recommended")
+	}
+
+	if service.Npm.HasDirectDependency("jest") {
+		config.Env["jest"] = true
+	}
+
+	// Vitest has no ESLint env, so declare its globals for the test files instead
+	if service.Npm.HasDirectDependency("vitest") {
+		config.Overrides = append(config.Overrides, map[string]interface{}{
+			"files":   []string{"**/*.test.*", "**/*.spec.*"},
+			"globals": vitestGlobals,
+		})
+	}
+
+	// Backend services shouldn't get browser globals, npm in a Go, PHP or Ruby project builds the frontend
+	if service.Npm.ProjectType() == language.ProjectTypeNode && !service.Go.Enabled && !service.PHP.Enabled && !service.Ruby.Enabled {
+		delete(config.Env, "browser")
+		config.Env["node"] = true
+	}
+
+	p.mergeProjectConfig(config)
+
 	fileBytes, _ := json.MarshalIndent(config, "", "\t")
 
+	// ESLint 9 no longer reads .eslintrc.json by default, JSON can't hold a marker so
+	// only a file with exactly the content projectl would write is known to be generated
+	if service.Npm.DependencyMajorVersion("eslint") >= 9 {
+		if err := removeStaleFile(".eslintrc.json", func(staleBytes []byte) bool {
+			return bytes.Equal(staleBytes, fileBytes)
+		}); err != nil {
+			return err
+		}
+
+		return p.generateFlatConfig(service, config)
+	}
+
+	// ESLint 8 can read a hand-written flat config too, so only a generated one is stale
+	if err := removeStaleFile("eslint.config.mjs", hasGeneratedMarker); err != nil {
+		return err
+	}
+
 	_ = os.WriteFile(".eslintrc.json", fileBytes, 0655)
 
 	return nil
 }
+
+func (p EslintGenerator) mergeProjectConfig(config *EslintConfig) {
+	for _, extend := range p.Config.Extends {
+		if !containsString(config.Extends, extend) {
+			config.Extends = append(config.Extends, extend)
+		}
+	}
+
+	for ruleName, ruleValue := range p.Config.Rules {
+		config.Rules[ruleName] = ruleValue
+	}
+
+	for env, enabled := range p.Config.Env {
+		config.Env[env] = enabled
+	}
+
+	config.IgnorePatterns = append(config.IgnorePatterns, p.Config.IgnorePatterns...)
+	config.Overrides = append(config.Overrides, p.Config.Overrides...)
+}
+
+func (p EslintGenerator) generateFlatConfig(service *projector.Service, config *EslintConfig) error {
+	missingDependencies := []string{}
+	for _, dependency := range eslintFlatConfigDependencies {
+		if !service.Npm.HasDirectDependency(dependency) {
+			missingDependencies = append(missingDependencies, dependency)
+		}
+	}
+
+	if len(missingDependencies) > 0 {
+		return fmt.Errorf("%w: eslint.config.mjs imports %s, add them to devDependencies", ErrMissingDependencies, strings.Join(missingDependencies, ", "))
+	}
+
+	payload := TemplatePayloadEslintFlatConfig{
+		Extends: config.Extends,
+	}
+
+	for env, enabled := range config.Env {
+		if enabled {
+			payload.Globals = append(payload.Globals, env)
+		}
+	}
+	sort.Strings(payload.Globals)
+
+	rules, err := jsObject(config.Rules, "\t\t")
+	if err != nil {
+		return err
+	}
+	payload.Rules = rules
+
+	// FlatCompat translates the eslintrc-only keys so they don't need a flat equivalent here
+	legacyConfig := map[string]interface{}{}
+	if len(config.Settings) > 0 {
+		legacyConfig["settings"] = config.Settings
+	}
+	if len(config.IgnorePatterns) > 0 {
+		legacyConfig["ignorePatterns"] = config.IgnorePatterns
+	}
+	if len(config.Overrides) > 0 {
+		legacyConfig["overrides"] = config.Overrides
+	}
+
+	if len(legacyConfig) > 0 {
+		legacy, err := jsObject(legacyConfig, "\t")
+		if err != nil {
+			return err
+		}
+		payload.Legacy = legacy
+	}
+
+	flatConfig := &projector.GeneratorTemplated{
+		TargetFile: "eslint.config.mjs",
+		Template:   template.Must(template.New("eslint").Parse(eslintFlatConfigTemplate)),
+		Payload:    payload,
+	}
+
+	return flatConfig.Generate(service)
+}
+
+// jsObject renders a value as a multi-line JavaScript object that passes the generated ESLint rules
+func jsObject(value interface{}, indent string) (string, error) {
+	// Round trip through JSON so any struct or map ends up as plain decoded values
+	valueBytes, err := json.Marshal(value)
+	if err != nil {
+		return "", err
+	}
+
+	object := map[string]interface{}{}
+	if err := json.Unmarshal(valueBytes, &object); err != nil {
+		return "", err
+	}
+
+	keys := []string{}
+	for key := range object {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := "{\n"
+	for _, key := range keys {
+		lines += indent + "\t" + jsLiteral(key) + ": " + jsLiteral(object[key]) + ",\n"
+	}
+
+	return lines + indent + "}", nil
+}
+
+// jsLiteral renders a decoded JSON value as single line JavaScript
+func jsLiteral(value interface{}) string {
+	switch typedValue := value.(type) {
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(typedValue) + "'"
+	case []interface{}:
+		values := []string{}
+		for _, item := range typedValue {
+			values = append(values, jsLiteral(item))
+		}
+
+		return "[" + strings.Join(values, ", ") + "]"
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		values := []string{}
+		for _, key := range keys {
+			values = append(values, jsLiteral(key)+": "+jsLiteral(typedValue[key]))
+		}
+
+		return "{ " + strings.Join(values, ", ") + " }"
+	case nil:
+		return "null"
+	}
+
+	// Booleans and numbers are the same in JSON and JavaScript
+	valueBytes, _ := json.Marshal(value)
+
+	return string(valueBytes)
+}
+
+func containsString(values []string, value string) bool {
+	for _, existingValue := range values {
+		if existingValue == value {
+			return true
+		}
+	}
+
+	return false
+}
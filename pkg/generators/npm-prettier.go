@@ -0,0 +1,39 @@
+package generators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+// PrettierGenerator generates the .prettierrc config file
+type PrettierGenerator struct {
+	Config map[string]interface{}
+}
+
+// Generate the config
+func (p PrettierGenerator) Generate(service *projector.Service) error {
+	if !service.Npm.Enabled || !service.Npm.HasDirectDependency("prettier") {
+		return nil
+	}
+
+	// Matches the indent, quotes, semi and comma-dangle rules of the EslintGenerator
+	config := map[string]interface{}{
+		"semi":          true,
+		"singleQuote":   true,
+		"trailingComma": "all",
+		"useTabs":       true,
+	}
+
+	for key, value := range p.Config {
+		config[key] = value
+	}
+
+	fileBytes, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(".prettierrc", append(fileBytes, '\n'), 0644)
+}
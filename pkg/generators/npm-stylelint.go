@@ -0,0 +1,64 @@
+package generators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+// StylelintConfig is the file format for the .stylelintrc.json
+type StylelintConfig struct {
+	Extends []string               `json:"extends"`
+	Rules   map[string]interface{} `json:"rules"`
+}
+
+// StylelintGenerator generates the .stylelintrc.json config file
+type StylelintGenerator struct {
+	Config configuration.StylelintConfig
+}
+
+// Generate the config
+func (p StylelintGenerator) Generate(service *projector.Service) error {
+	if !service.Npm.Enabled || !service.Npm.HasDirectDependency("stylelint") {
+		return nil
+	}
+
+	config := &StylelintConfig{
+		Extends: []string{},
+		Rules:   map[string]interface{}{},
+	}
+
+	if service.Npm.HasDependency("stylelint-config-standard") {
+		config.Extends = append(config.Extends, "stylelint-config-standard")
+	}
+
+	// Stylelint 15 dropped the stylistic rules in favor of prettier, an unknown version like latest is assumed current
+	majorVersion := service.Npm.DependencyMajorVersion("stylelint")
+	if majorVersion != 0 && majorVersion < 15 {
+		config.Rules["indentation"] = "tab"
+		config.Rules["string-quotes"] = "single"
+
+		if service.Npm.HasDependency("stylelint-config-prettier") {
+			config.Extends = append(config.Extends, "stylelint-config-prettier")
+		}
+	}
+
+	for _, extend := range p.Config.Extends {
+		if !containsString(config.Extends, extend) {
+			config.Extends = append(config.Extends, extend)
+		}
+	}
+
+	for ruleName, ruleValue := range p.Config.Rules {
+		config.Rules[ruleName] = ruleValue
+	}
+
+	fileBytes, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(".stylelintrc.json", append(fileBytes, '\n'), 0644)
+}
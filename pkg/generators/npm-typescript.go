@@ -0,0 +1,109 @@
+package generators
+
+import (
+	"encoding/json"
+	"io/ioutil"
+
+	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/language"
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+// TSConfig is the file format for the tsconfig.json
+type TSConfig struct {
+	CompilerOptions map[string]interface{} `json:"compilerOptions"`
+	Include         []string               `json:"include"`
+	Exclude         []string               `json:"exclude"`
+}
+
+// TypeScriptGenerator generates the tsconfig.json config file
+type TypeScriptGenerator struct {
+	Config configuration.TypeScriptConfig
+}
+
+// Generate the config
+func (p TypeScriptGenerator) Generate(service *projector.Service) error {
+	if !service.Npm.Enabled || !service.Npm.HasDirectDependency("typescript") {
+		return nil
+	}
+
+	config := &TSConfig{
+		CompilerOptions: map[string]interface{}{
+			"esModuleInterop":                  true,
+			"forceConsistentCasingInFileNames": true,
+			"resolveJsonModule":                true,
+			"skipLibCheck":                     true,
+			"strict":                           true,
+			"target":                           getTypeScriptTarget(service.Npm.MajorVersion()),
+		},
+		Include: []string{"src"},
+		Exclude: []string{"node_modules"},
+	}
+
+	switch service.Npm.ProjectType() {
+	case language.ProjectTypeNext:
+		config.CompilerOptions["allowJs"] = true
+		config.CompilerOptions["incremental"] = true
+		config.CompilerOptions["isolatedModules"] = true
+		config.CompilerOptions["jsx"] = "preserve"
+		config.CompilerOptions["lib"] = []string{"dom", "dom.iterable", "esnext"}
+		config.CompilerOptions["module"] = "esnext"
+		config.CompilerOptions["moduleResolution"] = "node"
+		config.CompilerOptions["noEmit"] = true
+		config.CompilerOptions["paths"] = map[string][]string{"@/*": {"./*"}}
+		config.Include = []string{"next-env.d.ts", "**/*.ts", "**/*.tsx"}
+	case language.ProjectTypeVue:
+		config.CompilerOptions["jsx"] = "preserve"
+		config.CompilerOptions["lib"] = []string{"dom", "esnext"}
+		config.CompilerOptions["module"] = "esnext"
+		config.CompilerOptions["moduleResolution"] = "node"
+		config.CompilerOptions["noEmit"] = true
+		config.CompilerOptions["paths"] = map[string][]string{"@/*": {"./src/*"}}
+		config.Include = []string{"src/**/*.ts", "src/**/*.tsx", "src/**/*.vue"}
+	case language.ProjectTypeNode:
+		config.CompilerOptions["module"] = "commonjs"
+		config.CompilerOptions["outDir"] = "dist"
+		config.CompilerOptions["rootDir"] = "src"
+	default:
+		config.CompilerOptions["jsx"] = "react-jsx"
+		config.CompilerOptions["lib"] = []string{"dom", "dom.iterable", "esnext"}
+		config.CompilerOptions["module"] = "esnext"
+		config.CompilerOptions["moduleResolution"] = "node"
+		config.CompilerOptions["noEmit"] = true
+	}
+
+	for optionName, optionValue := range p.Config.CompilerOptions {
+		config.CompilerOptions[optionName] = optionValue
+	}
+
+	if len(p.Config.Include) > 0 {
+		config.Include = p.Config.Include
+	}
+
+	if len(p.Config.Exclude) > 0 {
+		config.Exclude = p.Config.Exclude
+	}
+
+	fileBytes, err := json.MarshalIndent(config, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile("tsconfig.json", append(fileBytes, '\n'), 0644)
+}
+
+// getTypeScriptTarget gets the newest ECMAScript target the node version fully supports
+func getTypeScriptTarget(nodeMajorVersion int) string {
+	switch {
+	case nodeMajorVersion >= 18:
+		return "ES2022"
+	case nodeMajorVersion >= 16:
+		return "ES2021"
+	case nodeMajorVersion >= 14:
+		return "ES2020"
+	case nodeMajorVersion >= 12:
+		return "ES2019"
+	}
+
+	return "ES2018"
+}
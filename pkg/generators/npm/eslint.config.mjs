@@ -0,0 +1,24 @@
+// Generated by projectl, edit the projectl config instead of this file
+import path from 'path';
+import { fileURLToPath } from 'url';
+import { FlatCompat } from '@eslint/eslintrc';
+import js from '@eslint/js';
+import globals from 'globals';
+
+const compat = new FlatCompat({
+	baseDirectory: path.dirname(fileURLToPath(import.meta.url)),
+	recommendedConfig: js.configs.recommended,
+});
+
+export default [
+	...compat.extends({{ range $index, $extend := .Extends }}{{ if $index }}, {{ end }}'{{ $extend }}'{{ end }}),
+	{
+		languageOptions: {
+			globals: {
+{{ range .Globals }}				...globals.{{ . }},
+{{ end }}			},
+		},
+		rules: {{ .Rules }},
+	},
+{{ if .Legacy }}	...compat.config({{ .Legacy }}),
+{{ end }}];
@@ -2,7 +2,9 @@ package generators
 
 import (
 	"os"
+	"text/template"
 
+	"github.com/aaronellington/projectl/pkg/configuration"
 	"github.com/aaronellington/projectl/pkg/projector"
 
 	// For embed
@@ -10,13 +12,53 @@ import (
 )
 
 //go:embed php/php_cs.php
-var phpCSFixerConfigFile []byte
+var phpCSFixerConfigTemplate string
 
 //go:embed php/phpcs.xml
-var phpCodeSnifferConfigFile []byte
+var phpCodeSnifferConfigTemplate string
 
-// PHPConfig generates the .php_cs config file
-type PHPConfig struct{}
+//go:embed php/phpstan.neon
+var phpStanConfigTemplate string
+
+//go:embed php/phpunit.xml
+var phpUnitConfigTemplate string
+
+// phpStanExtensions are the phpstan extensions to include when they are required
+var phpStanExtensions = []string{
+	"phpstan/phpstan-symfony",
+	"phpstan/phpstan-doctrine",
+	"phpstan/phpstan-phpunit",
+}
+
+// TemplatePayloadPHPCodeSniffer template payload
+type TemplatePayloadPHPCodeSniffer struct {
+	Paths        []string
+	PHPVersionID int
+}
+
+// TemplatePayloadPHPStan template payload
+type TemplatePayloadPHPStan struct {
+	Includes     []string
+	Level        string
+	Paths        []string
+	ExcludePaths []string
+}
+
+// TemplatePayloadPHPUnit template payload
+type TemplatePayloadPHPUnit struct {
+	Bootstrap      string
+	TestPaths      []string
+	SourcePaths    []string
+	SourceElement  bool
+	CoverageClover string
+}
+
+// PHPConfig generates the php linter and test config files
+type PHPConfig struct {
+	PHPCSFixer configuration.PHPCSFixerConfig
+	PHPStan    configuration.PHPStanConfig
+	PHPUnit    configuration.PHPUnitConfig
+}
 
 // Generate the config
 func (p PHPConfig) Generate(service *projector.Service) error {
@@ -24,13 +66,98 @@ func (p PHPConfig) Generate(service *projector.Service) error {
 		return nil
 	}
 
-	if err := os.WriteFile(".php_cs", phpCSFixerConfigFile, 0655); err != nil {
+	payload := TemplatePayloadPHPCodeSniffer{
+		Paths:        getPHPLintPaths(service),
+		PHPVersionID: service.PHP.VersionID(),
+	}
+
+	if err := generatePHPCSFixerConfig(service, p.PHPCSFixer); err != nil {
+		return err
+	}
+
+	codeSniffer := &projector.GeneratorTemplated{
+		TargetFile: ".phpcs.xml",
+		Template:   template.Must(template.New("phpcs").Parse(phpCodeSnifferConfigTemplate)),
+		Payload:    payload,
+	}
+
+	if err := codeSniffer.Generate(service); err != nil {
 		return err
 	}
 
-	if err := os.WriteFile(".phpcs.xml", phpCodeSnifferConfigFile, 0655); err != nil {
+	phpStan := &projector.GeneratorTemplated{
+		TargetFile: "phpstan.neon.dist",
+		Template:   template.Must(template.New("phpstan").Parse(phpStanConfigTemplate)),
+		Payload:    p.getPHPStanPayload(service),
+	}
+	if err := phpStan.Generate(service); err != nil {
 		return err
 	}
 
-	return nil
+	phpUnit := &projector.GeneratorTemplated{
+		TargetFile: "phpunit.xml.dist",
+		Template:   template.Must(template.New("phpunit").Parse(phpUnitConfigTemplate)),
+		Payload:    p.getPHPUnitPayload(service),
+	}
+
+	return phpUnit.Generate(service)
+}
+
+func (p PHPConfig) getPHPStanPayload(service *projector.Service) TemplatePayloadPHPStan {
+	payload := TemplatePayloadPHPStan{
+		Level:        string(p.PHPStan.Level),
+		Paths:        service.PHP.SourcePaths(),
+		ExcludePaths: p.PHPStan.ExcludePaths,
+	}
+
+	if payload.Level == "" {
+		payload.Level = "max"
+	}
+
+	if _, err := os.Stat("phpstan-baseline.neon"); err == nil {
+		payload.Includes = append(payload.Includes, "phpstan-baseline.neon")
+	}
+
+	// The extension installer includes the extensions on its own
+	if !service.PHP.Requires("phpstan/extension-installer") {
+		for _, extension := range phpStanExtensions {
+			if service.PHP.Requires(extension) {
+				payload.Includes = append(payload.Includes, "vendor/"+extension+"/extension.neon")
+			}
+		}
+	}
+
+	return payload
+}
+
+func (p PHPConfig) getPHPUnitPayload(service *projector.Service) TemplatePayloadPHPUnit {
+	payload := TemplatePayloadPHPUnit{
+		Bootstrap:      p.PHPUnit.Bootstrap,
+		TestPaths:      service.PHP.TestPaths(),
+		SourcePaths:    service.PHP.SourcePaths(),
+		SourceElement:  service.PHP.PackageMajorVersion("phpunit/phpunit") >= 10,
+		CoverageClover: p.PHPUnit.CoverageClover,
+	}
+
+	if payload.Bootstrap == "" {
+		payload.Bootstrap = "vendor/autoload.php"
+	}
+
+	return payload
+}
+
+// getPHPLintPaths gets the source and test directories, without duplicates
+func getPHPLintPaths(service *projector.Service) []string {
+	paths := []string{}
+	seen := map[string]bool{}
+	for _, path := range append(service.PHP.SourcePaths(), service.PHP.TestPaths()...) {
+		if seen[path] {
+			continue
+		}
+
+		seen[path] = true
+		paths = append(paths, path)
+	}
+
+	return paths
 }
@@ -0,0 +1,164 @@
+package generators
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+// TemplatePayloadPHPCSFixer template payload
+type TemplatePayloadPHPCSFixer struct {
+	Paths   []string
+	Exclude []string
+	Rules   []TemplatePHPCSFixerRule
+}
+
+// TemplatePHPCSFixerRule is a rule of the php-cs-fixer config, with the value already in PHP syntax
+type TemplatePHPCSFixerRule struct {
+	Name  string
+	Value string
+}
+
+// getPHPCSFixerHouseRules gets the default rules, php-cs-fixer v3 renamed a few of them
+func getPHPCSFixerHouseRules(majorVersion int) map[string]interface{} {
+	sortAlgorithmOption := "sort_algorithm"
+	if majorVersion < 3 {
+		sortAlgorithmOption = "sortAlgorithm"
+	}
+
+	rules := map[string]interface{}{
+		"@PSR2":                       true,
+		"array_syntax":                map[string]interface{}{"syntax": "short"},
+		"blank_line_before_statement": map[string]interface{}{"statements": []interface{}{"return"}},
+		"global_namespace_import":     true,
+		"native_function_casing":      true,
+		"no_extra_blank_lines":        true,
+		"no_unused_imports":           true,
+		"ordered_class_elements": map[string]interface{}{
+			sortAlgorithmOption: "alpha",
+			"order": []interface{}{
+				"use_trait", "constant_public", "constant_protected", "constant_private",
+				"property_public", "property_protected", "property_private",
+				"construct", "destruct", "magic", "phpunit",
+				"method_public", "method_protected", "method_private",
+			},
+		},
+		"ordered_imports":                     true,
+		"phpdoc_add_missing_param_annotation": map[string]interface{}{"only_untyped": true},
+		"phpdoc_indent":                       true,
+		"phpdoc_line_span":                    map[string]interface{}{"const": "single", "method": "multi", "property": "single"},
+		"phpdoc_no_package":                   true,
+		"phpdoc_order":                        true,
+		"phpdoc_scalar":                       true,
+		"phpdoc_separation":                   true,
+		"phpdoc_trim_consecutive_blank_line_separation": true,
+		"phpdoc_trim":  true,
+		"single_quote": true,
+	}
+
+	// v3 removed align_double_arrow in favour of per operator alignment
+	if majorVersion < 3 {
+		rules["binary_operator_spaces"] = map[string]interface{}{"align_double_arrow": true}
+		rules["trailing_comma_in_multiline_array"] = true
+	} else {
+		rules["binary_operator_spaces"] = map[string]interface{}{"operators": map[string]interface{}{"=>": "align_single_space_minimal"}}
+		rules["trailing_comma_in_multiline"] = map[string]interface{}{"elements": []interface{}{"arrays"}}
+	}
+
+	return rules
+}
+
+// getPHPCSFixerTargetFile gets the config file name php-cs-fixer reads, and the one older or newer versions read
+func getPHPCSFixerTargetFile(service *projector.Service) (string, string, int) {
+	majorVersion := service.PHP.PackageMajorVersion("friendsofphp/php-cs-fixer")
+	if majorVersion == 0 {
+		majorVersion = 3
+	}
+
+	if majorVersion < 3 {
+		return ".php_cs", ".php-cs-fixer.dist.php", majorVersion
+	}
+
+	return ".php-cs-fixer.dist.php", ".php_cs", majorVersion
+}
+
+func generatePHPCSFixerConfig(service *projector.Service, config configuration.PHPCSFixerConfig) error {
+	targetFile, staleFile, majorVersion := getPHPCSFixerTargetFile(service)
+
+	if err := removeStaleFile(staleFile, hasGeneratedMarker); err != nil {
+		return err
+	}
+
+	rules := getPHPCSFixerHouseRules(majorVersion)
+	for ruleName, ruleValue := range config.Rules {
+		rules[ruleName] = ruleValue
+	}
+
+	payload := TemplatePayloadPHPCSFixer{
+		Paths:   config.Paths,
+		Exclude: config.Exclude,
+	}
+
+	if len(payload.Paths) == 0 {
+		payload.Paths = getPHPLintPaths(service)
+	}
+
+	ruleNames := []string{}
+	for ruleName := range rules {
+		ruleNames = append(ruleNames, ruleName)
+	}
+	sort.Strings(ruleNames)
+
+	for _, ruleName := range ruleNames {
+		payload.Rules = append(payload.Rules, TemplatePHPCSFixerRule{
+			Name:  ruleName,
+			Value: phpLiteral(rules[ruleName]),
+		})
+	}
+
+	csFixer := &projector.GeneratorTemplated{
+		TargetFile: targetFile,
+		Template:   template.Must(template.New("php_cs").Parse(phpCSFixerConfigTemplate)),
+		Payload:    payload,
+	}
+
+	return csFixer.Generate(service)
+}
+
+// phpLiteral converts a decoded JSON value to PHP syntax
+func phpLiteral(value interface{}) string {
+	switch typedValue := value.(type) {
+	case bool:
+		return strconv.FormatBool(typedValue)
+	case float64:
+		return strconv.FormatFloat(typedValue, 'f', -1, 64)
+	case string:
+		return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(typedValue) + "'"
+	case []interface{}:
+		values := []string{}
+		for _, item := range typedValue {
+			values = append(values, phpLiteral(item))
+		}
+
+		return "[" + strings.Join(values, ", ") + "]"
+	case map[string]interface{}:
+		keys := []string{}
+		for key := range typedValue {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+
+		values := []string{}
+		for _, key := range keys {
+			values = append(values, phpLiteral(key)+" => "+phpLiteral(typedValue[key]))
+		}
+
+		return "[" + strings.Join(values, ", ") + "]"
+	}
+
+	return "null"
+}
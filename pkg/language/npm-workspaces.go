@@ -0,0 +1,214 @@
+package language
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// Monorepo tools
+const (
+	MonorepoToolTurbo = "turbo"
+	MonorepoToolNx    = "nx"
+)
+
+// NpmWorkspace is a package of an npm, yarn, pnpm or bun workspace
+type NpmWorkspace struct {
+	Path        string
+	packageJSON PackageDotJSON
+}
+
+// Name gets the package name, falling back to the path for unnamed packages
+func (workspace NpmWorkspace) Name() string {
+	if workspace.packageJSON.Name == "" {
+		return workspace.Path
+	}
+
+	return workspace.packageJSON.Name
+}
+
+// HasScript checks if a script is defined in the workspace package
+func (workspace NpmWorkspace) HasScript(scriptName string) bool {
+	return workspace.packageJSON.Scripts[scriptName] != ""
+}
+
+// NpmWorkspaceGlobs are the workspace patterns, either a list or yarn's {"packages": [...]} form
+type NpmWorkspaceGlobs []string
+
+// UnmarshalJSON accepts both forms of the workspaces field
+func (globs *NpmWorkspaceGlobs) UnmarshalJSON(data []byte) error {
+	var yarnWorkspaces struct {
+		Packages []string `json:"packages"`
+	}
+	if err := json.Unmarshal(data, &yarnWorkspaces); err == nil {
+		*globs = yarnWorkspaces.Packages
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(globs))
+}
+
+// IsWorkspace checks if the project is a monorepo of workspace packages
+func (languageNpm Npm) IsWorkspace() bool {
+	return len(languageNpm.Workspaces) > 0
+}
+
+// RunAllCommand gets the command to run a script across the project, using the monorepo tool or workspaces when there are any
+func (languageNpm Npm) RunAllCommand(scriptName string) string {
+	switch languageNpm.MonorepoTool {
+	case MonorepoToolTurbo:
+		return languageNpm.ExecCommand("turbo run " + scriptName)
+	case MonorepoToolNx:
+		// nx affected needs the git history and a base branch, neither of which the Docker
+		// build has, and on main it compares main to itself and runs nothing
+		return languageNpm.ExecCommand("nx run-many --target=" + scriptName)
+	}
+
+	if !languageNpm.IsWorkspace() {
+		return languageNpm.RunCommand(scriptName)
+	}
+
+	switch languageNpm.PackageManager {
+	case PackageManagerYarn:
+		// Yarn 1 has no foreach, and without a pinned version it's the one most likely installed
+		if !strings.HasPrefix(languageNpm.PackageManagerVersion(), "1.") && languageNpm.PackageManagerVersion() != "" {
+			return "yarn workspaces foreach --all run " + scriptName
+		}
+
+		return "yarn workspaces run " + scriptName
+	case PackageManagerPnpm:
+		return "pnpm --recursive --if-present run " + scriptName
+	case PackageManagerBun:
+		return "bun run --filter '*' " + scriptName
+	}
+
+	return "npm run " + scriptName + " --workspaces --if-present"
+}
+
+// StartCommand gets the command to start the project, from the first workspace with a start script when the root has none
+func (languageNpm Npm) StartCommand() []string {
+	if languageNpm.HasScript("start") {
+		return []string{languageNpm.PackageManager, "run", "start"}
+	}
+
+	for _, workspace := range languageNpm.Workspaces {
+		if !workspace.HasScript("start") {
+			continue
+		}
+
+		switch languageNpm.PackageManager {
+		case PackageManagerYarn:
+			return []string{"yarn", "workspace", workspace.Name(), "run", "start"}
+		case PackageManagerPnpm:
+			return []string{"pnpm", "--filter", workspace.Name(), "run", "start"}
+		case PackageManagerBun:
+			return []string{"bun", "run", "--filter", workspace.Name(), "start"}
+		}
+
+		return []string{"npm", "run", "start", "--workspace=" + workspace.Path}
+	}
+
+	return []string{languageNpm.PackageManager, "run", "start"}
+}
+
+// detectMonorepoTool detects the Turborepo or Nx config file
+func detectMonorepoTool() string {
+	if _, err := os.Stat("turbo.json"); err == nil {
+		return MonorepoToolTurbo
+	}
+
+	if _, err := os.Stat("nx.json"); err == nil {
+		return MonorepoToolNx
+	}
+
+	return ""
+}
+
+// findWorkspaces enumerates the workspace packages, pnpm keeps its patterns in pnpm-workspace.yaml
+func findWorkspaces(globs []string) ([]NpmWorkspace, error) {
+	if fileBytes, err := ioutil.ReadFile("pnpm-workspace.yaml"); err == nil {
+		globs = append(globs, parsePnpmWorkspaceGlobs(fileBytes)...)
+	}
+
+	paths := map[string]bool{}
+	for _, glob := range globs {
+		exclude := strings.HasPrefix(glob, "!")
+		glob = strings.TrimPrefix(glob, "!")
+
+		// filepath.Match has no globstar, a single level covers the usual apps/* and packages/* layouts
+		glob = strings.ReplaceAll(strings.TrimSuffix(glob, "/"), "**", "*")
+
+		matches, err := filepath.Glob(glob)
+		if err != nil {
+			return nil, fmt.Errorf("%w while matching workspace %s", err, glob)
+		}
+
+		for _, match := range matches {
+			if exclude {
+				delete(paths, match)
+				continue
+			}
+
+			if _, err := os.Stat(filepath.Join(match, "package.json")); err == nil {
+				paths[match] = true
+			}
+		}
+	}
+
+	workspaces := []NpmWorkspace{}
+	for path := range paths {
+		workspace := NpmWorkspace{
+			Path: filepath.ToSlash(path),
+		}
+
+		fileBytes, err := ioutil.ReadFile(filepath.Join(path, "package.json"))
+		if err != nil {
+			return nil, fmt.Errorf("%w while reading %s/package.json", err, path)
+		}
+
+		if err := json.Unmarshal(fileBytes, &workspace.packageJSON); err != nil {
+			return nil, fmt.Errorf("%w while parsing %s/package.json", err, path)
+		}
+
+		workspaces = append(workspaces, workspace)
+	}
+
+	sort.Slice(workspaces, func(i, j int) bool {
+		return workspaces[i].Path < workspaces[j].Path
+	})
+
+	return workspaces, nil
+}
+
+// parsePnpmWorkspaceGlobs reads the packages list of pnpm-workspace.yaml
+func parsePnpmWorkspaceGlobs(fileBytes []byte) []string {
+	globs := []string{}
+	inPackages := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if strings.TrimSpace(line) == "" || strings.HasPrefix(strings.TrimSpace(line), "#") {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") && !strings.HasPrefix(line, "-") {
+			inPackages = strings.TrimSpace(line) == "packages:"
+			continue
+		}
+
+		item := strings.TrimSpace(line)
+		if inPackages && strings.HasPrefix(item, "-") {
+			globs = append(globs, strings.Trim(strings.TrimSpace(strings.TrimPrefix(item, "-")), `'"`))
+		}
+	}
+
+	return globs
+}
@@ -1,14 +1,76 @@
 package language
 
 import (
+	"bufio"
+	"bytes"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
 )
 
+// Package managers
+const (
+	PackageManagerNpm  = "npm"
+	PackageManagerYarn = "yarn"
+	PackageManagerPnpm = "pnpm"
+	PackageManagerBun  = "bun"
+)
+
+// Project types
+const (
+	ProjectTypeNext    = "next"
+	ProjectTypeVue     = "vue"
+	ProjectTypeReact   = "react"
+	ProjectTypeSvelte  = "svelte"
+	ProjectTypeAngular = "angular"
+	ProjectTypeNode    = "node"
+	ProjectTypeBrowser = "browser"
+)
+
+// frameworkDependencies maps the dependencies that identify a frontend framework, in order of detection precedence
+var frameworkDependencies = []struct {
+	Dependency  string
+	ProjectType string
+}{
+	{Dependency: "next", ProjectType: ProjectTypeNext},
+	{Dependency: "@angular/core", ProjectType: ProjectTypeAngular},
+	{Dependency: "vue", ProjectType: ProjectTypeVue},
+	{Dependency: "svelte", ProjectType: ProjectTypeSvelte},
+	{Dependency: "react", ProjectType: ProjectTypeReact},
+}
+
+// bundlerDependencies are the dependencies that mean the project builds code for the browser
+var bundlerDependencies = []string{
+	"webpack",
+	"vite",
+	"parcel",
+	"esbuild",
+	"rollup",
+	"@symfony/webpack-encore",
+	"laravel-mix",
+}
+
+// lockFiles maps each package manager to its lock file, in order of detection precedence
+var lockFiles = []struct {
+	PackageManager string
+	FileName       string
+}{
+	{PackageManager: PackageManagerPnpm, FileName: "pnpm-lock.yaml"},
+	{PackageManager: PackageManagerYarn, FileName: "yarn.lock"},
+	{PackageManager: PackageManagerBun, FileName: "bun.lockb"},
+	{PackageManager: PackageManagerNpm, FileName: "package-lock.json"},
+}
+
 // NewNpm generates a ready-to-use StateNpm
 func NewNpm() (*Npm, error) {
-	languageNpm := &Npm{}
+	languageNpm := &Npm{
+		PackageManager:     PackageManagerNpm,
+		lockedDependencies: make(map[string]string),
+	}
 	fileBytes, err := ioutil.ReadFile("package.json")
 	if err != nil {
 		// package.json file not able to be opened,
@@ -23,22 +85,83 @@ func NewNpm() (*Npm, error) {
 		return nil, fmt.Errorf("%w while parsing package.json", err)
 	}
 
-	lockFileBytes, err := ioutil.ReadFile("package-lock.json")
-	if err == nil {
-		err = json.Unmarshal(lockFileBytes, &languageNpm.packageLockJSON)
-		if err != nil {
-			return nil, fmt.Errorf("%w while parsing package-lock.json", err)
+	for _, lockFile := range lockFiles {
+		if _, err := os.Stat(lockFile.FileName); err == nil {
+			languageNpm.PackageManager = lockFile.PackageManager
+			languageNpm.LockFile = lockFile.FileName
+			break
 		}
 	}
 
+	// The packageManager field is authoritative when present
+	if languageNpm.packageJSON.PackageManager != "" {
+		languageNpm.PackageManager = strings.SplitN(languageNpm.packageJSON.PackageManager, "@", 2)[0]
+	}
+
+	languageNpm.version = detectNodeVersion(languageNpm.packageJSON.Engines.Node)
+	languageNpm.MonorepoTool = detectMonorepoTool()
+
+	languageNpm.Workspaces, err = findWorkspaces(languageNpm.packageJSON.Workspaces)
+	if err != nil {
+		return nil, err
+	}
+
+	if languageNpm.LockFile == "" {
+		return languageNpm, nil
+	}
+
+	lockFileBytes, err := ioutil.ReadFile(languageNpm.LockFile)
+	if err != nil {
+		return nil, fmt.Errorf("%w while reading %s", err, languageNpm.LockFile)
+	}
+
+	switch languageNpm.LockFile {
+	case "package-lock.json":
+		err = json.Unmarshal(lockFileBytes, &languageNpm.packageLockJSON)
+	case "yarn.lock":
+		err = languageNpm.parseYarnLock(lockFileBytes)
+	case "pnpm-lock.yaml":
+		err = languageNpm.parsePnpmLock(lockFileBytes)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("%w while parsing %s", err, languageNpm.LockFile)
+	}
+
 	return languageNpm, nil
 }
 
 // Npm is the state of the npm project
 type Npm struct {
-	Enabled         bool
-	packageJSON     PackageDotJSON
-	packageLockJSON PackageLockDotJSON
+	Enabled            bool
+	PackageManager     string
+	LockFile           string
+	MonorepoTool       string
+	Workspaces         []NpmWorkspace
+	version            string
+	packageJSON        PackageDotJSON
+	packageLockJSON    PackageLockDotJSON
+	lockedDependencies map[string]string
+}
+
+// Version gets the resolved node version
+func (languageNpm Npm) Version() string {
+	if languageNpm.version == "" {
+		return "16"
+	}
+
+	return languageNpm.version
+}
+
+// MajorVersion gets the major version of the resolved node version
+func (languageNpm Npm) MajorVersion() int {
+	major, _ := strconv.Atoi(majorVersionMatcher.FindString(languageNpm.Version()))
+
+	return major
+}
+
+// SetVersion overrides the detected node version
+func (languageNpm *Npm) SetVersion(version string) {
+	languageNpm.version = version
 }
 
 // HasScript checks if a script is defined
@@ -50,19 +173,300 @@ func (languageNpm Npm) HasScript(targetName string) bool {
 
 // HasDependency checks if the project has a dependency
 func (languageNpm Npm) HasDependency(targetName string) bool {
-	_, found := languageNpm.packageLockJSON.Dependencies[targetName]
+	switch languageNpm.LockFile {
+	case "package-lock.json":
+		return languageNpm.packageLockJSON.HasDependency(targetName)
+	case "yarn.lock", "pnpm-lock.yaml":
+		_, found := languageNpm.lockedDependencies[targetName]
 
-	return found
+		return found
+	}
+
+	// Without a readable lock file (bun.lockb is binary), rely on package.json instead
+	return languageNpm.packageJSON.HasDependency(targetName)
+}
+
+// HasDirectDependency checks if the project, or one of its workspace packages, lists the
+// dependency in package.json, unlike the lock file it leaves out the dependencies of dependencies
+func (languageNpm Npm) HasDirectDependency(targetName string) bool {
+	if languageNpm.packageJSON.HasDependency(targetName) {
+		return true
+	}
+
+	for _, workspace := range languageNpm.Workspaces {
+		if workspace.packageJSON.HasDependency(targetName) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// DependencyVersion gets the installed version of a dependency, or the package.json constraint without a lock file
+func (languageNpm Npm) DependencyVersion(targetName string) string {
+	switch languageNpm.LockFile {
+	case "package-lock.json":
+		return languageNpm.packageLockJSON.DependencyVersion(targetName)
+	case "yarn.lock", "pnpm-lock.yaml":
+		return languageNpm.lockedDependencies[targetName]
+	}
+
+	if version, found := languageNpm.packageJSON.Dependencies[targetName]; found {
+		return version
+	}
+
+	return languageNpm.packageJSON.DevDependencies[targetName]
+}
+
+// DependencyMajorVersion gets the major version of a dependency, or 0 when it can't be determined
+func (languageNpm Npm) DependencyMajorVersion(targetName string) int {
+	major, _ := strconv.Atoi(majorVersionMatcher.FindString(languageNpm.DependencyVersion(targetName)))
+
+	return major
+}
+
+// ProjectType detects the kind of project, a frontend framework, a browser bundle or a node-only project,
+// bundlers like vite and esbuild are installed by tools like vitest and tsx so only direct dependencies count
+func (languageNpm Npm) ProjectType() string {
+	for _, frameworkDependency := range frameworkDependencies {
+		if languageNpm.HasDirectDependency(frameworkDependency.Dependency) {
+			return frameworkDependency.ProjectType
+		}
+	}
+
+	for _, bundlerDependency := range bundlerDependencies {
+		if languageNpm.HasDirectDependency(bundlerDependency) {
+			return ProjectTypeBrowser
+		}
+	}
+
+	return ProjectTypeNode
+}
+
+// InstallCommand gets the command to install the dependencies
+func (languageNpm Npm) InstallCommand() string {
+	if languageNpm.PackageManager == PackageManagerNpm {
+		return "npm install --no-save"
+	}
+
+	return languageNpm.PackageManager + " install"
+}
+
+// RunCommand gets the command to run a package.json script
+func (languageNpm Npm) RunCommand(scriptName string) string {
+	return languageNpm.PackageManager + " run " + scriptName
+}
+
+// ExecCommand gets the command to run a binary installed by a dependency
+func (languageNpm Npm) ExecCommand(binName string) string {
+	switch languageNpm.PackageManager {
+	case PackageManagerYarn:
+		return "yarn " + binName
+	case PackageManagerPnpm:
+		return "pnpm exec " + binName
+	case PackageManagerBun:
+		return "bunx " + binName
+	}
+
+	return "npx " + binName
+}
+
+// PackageManagerVersion gets the version pinned in the packageManager field
+func (languageNpm Npm) PackageManagerVersion() string {
+	parts := strings.SplitN(languageNpm.packageJSON.PackageManager, "@", 2)
+	if len(parts) != 2 {
+		return ""
+	}
+
+	// Strip the optional integrity hash, e.g. pnpm@8.6.0+sha256.abc
+	return strings.SplitN(parts[1], "+", 2)[0]
+}
+
+func (languageNpm *Npm) parseYarnLock(fileBytes []byte) error {
+	entryNames := []string{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		// The version of an entry is on an indented line, e.g. version "12.2.0" or version: 12.2.0
+		if strings.HasPrefix(line, " ") {
+			fields := strings.Fields(line)
+			if len(fields) == 2 && strings.TrimSuffix(fields[0], ":") == "version" {
+				for _, name := range entryNames {
+					languageNpm.lockedDependencies[name] = strings.Trim(fields[1], `"`)
+				}
+			}
+			continue
+		}
+
+		// Entries are the only unindented lines, e.g. "next@^12.0.0", next@12:
+		entryNames = []string{}
+		for _, descriptor := range strings.Split(strings.TrimSuffix(line, ":"), ",") {
+			descriptor = strings.Trim(strings.TrimSpace(descriptor), `"`)
+			if name := packageNameFromDescriptor(descriptor); name != "" {
+				languageNpm.lockedDependencies[name] = ""
+				entryNames = append(entryNames, name)
+			}
+		}
+	}
+
+	return scanner.Err()
+}
+
+var legacyPnpmKeyMatcher = regexp.MustCompile(`^((@[^/]+/)?[^/@]+)/(\d[^_]*)`)
+
+func (languageNpm *Npm) parsePnpmLock(fileBytes []byte) error {
+	inPackages := false
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, " ") {
+			inPackages = line == "packages:"
+			continue
+		}
+
+		// Package keys are indented by exactly two spaces, e.g. /next/12.0.0: or next@12.0.0:
+		if !inPackages || !strings.HasPrefix(line, "  ") || strings.HasPrefix(line, "   ") {
+			continue
+		}
+
+		key := strings.Trim(strings.TrimSuffix(strings.TrimSpace(line), ":"), `'"`)
+		key = strings.SplitN(key, "(", 2)[0]
+		key = strings.TrimPrefix(key, "/")
+
+		// Legacy lock files use /name/version instead of name@version
+		if match := legacyPnpmKeyMatcher.FindStringSubmatch(key); match != nil {
+			languageNpm.lockedDependencies[match[1]] = match[3]
+			continue
+		}
+
+		if name := packageNameFromDescriptor(key); name != "" {
+			languageNpm.lockedDependencies[name] = strings.TrimPrefix(key, name+"@")
+		}
+	}
+
+	return scanner.Err()
+}
+
+var majorVersionMatcher = regexp.MustCompile(`\d+`)
+
+var nodeVersionMatcher = regexp.MustCompile(`\d+(\.\d+){0,2}`)
+
+// detectNodeVersion resolves the node version from the version files, falling back to engines.node
+func detectNodeVersion(enginesNode string) string {
+	for _, versionFile := range []string{".nvmrc", ".node-version"} {
+		fileBytes, err := ioutil.ReadFile(versionFile)
+		if err != nil {
+			continue
+		}
+
+		// Aliases like lts/* can't be resolved without nvm, so keep looking
+		if version := nodeVersionMatcher.FindString(string(fileBytes)); version != "" {
+			return version
+		}
+	}
+
+	if fileBytes, err := ioutil.ReadFile(".tool-versions"); err == nil {
+		for _, line := range strings.Split(string(fileBytes), "\n") {
+			fields := strings.Fields(line)
+			if len(fields) >= 2 && fields[0] == "nodejs" {
+				return nodeVersionMatcher.FindString(fields[1])
+			}
+		}
+	}
+
+	// Constraints like ">=16 <19" or "^18.0.0" resolve to their lowest major version
+	if match := nodeVersionMatcher.FindString(enginesNode); match != "" {
+		return strings.SplitN(match, ".", 2)[0]
+	}
+
+	return ""
+}
+
+// packageNameFromDescriptor gets the package name from a name@range descriptor
+func packageNameFromDescriptor(descriptor string) string {
+	if descriptor == "" {
+		return ""
+	}
+
+	// Skip the first character so scoped packages like @types/react keep their scope
+	index := strings.Index(descriptor[1:], "@")
+	if index < 0 {
+		return ""
+	}
+
+	return descriptor[:index+1]
 }
 
 // PackageDotJSON is the structure of the package.json file
 type PackageDotJSON struct {
+	Name            string            `json:"name"`
+	Workspaces      NpmWorkspaceGlobs `json:"workspaces"`
 	Dependencies    map[string]string `json:"dependencies"`
 	DevDependencies map[string]string `json:"devDependencies"`
 	Scripts         map[string]string `json:"scripts"`
+	PackageManager  string            `json:"packageManager"`
+	Engines         struct {
+		Node string `json:"node"`
+	} `json:"engines"`
+}
+
+// HasDependency checks if the package is listed in dependencies or devDependencies
+func (packageJSON PackageDotJSON) HasDependency(targetName string) bool {
+	if _, found := packageJSON.Dependencies[targetName]; found {
+		return true
+	}
+
+	_, found := packageJSON.DevDependencies[targetName]
+
+	return found
 }
 
 // PackageLockDotJSON is the structure of the package-lock.json file
 type PackageLockDotJSON struct {
-	Dependencies map[string]interface{} `json:"dependencies"`
+	LockfileVersion int                                     `json:"lockfileVersion"`
+	Dependencies    map[string]PackageLockDotJSONDependency `json:"dependencies"`
+	Packages        map[string]PackageLockDotJSONDependency `json:"packages"`
+}
+
+// usePackages checks if the lock file should be read from packages
+func (packageLockJSON PackageLockDotJSON) usePackages() bool {
+	// Lockfile v2 has both formats, v3 (npm 9+) only has packages
+	return packageLockJSON.LockfileVersion >= 2 && packageLockJSON.Packages != nil
+}
+
+// HasDependency checks if the package is installed according to the lock file
+func (packageLockJSON PackageLockDotJSON) HasDependency(targetName string) bool {
+	if packageLockJSON.usePackages() {
+		_, found := packageLockJSON.Packages["node_modules/"+targetName]
+
+		return found
+	}
+
+	_, found := packageLockJSON.Dependencies[targetName]
+
+	return found
+}
+
+// DependencyVersion gets the installed version of the package according to the lock file
+func (packageLockJSON PackageLockDotJSON) DependencyVersion(targetName string) string {
+	if packageLockJSON.usePackages() {
+		return packageLockJSON.Packages["node_modules/"+targetName].Version
+	}
+
+	return packageLockJSON.Dependencies[targetName].Version
+}
+
+// PackageLockDotJSONDependency is an installed package in the package-lock.json file
+type PackageLockDotJSONDependency struct {
+	Version string `json:"version"`
 }
@@ -5,8 +5,27 @@ import (
 	"fmt"
 	"io/ioutil"
 	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 )
 
+// Frameworks
+const (
+	FrameworkSymfony = "symfony"
+	FrameworkLaravel = "laravel"
+)
+
+// frameworkPackages maps the packages that identify a framework, in order of detection precedence
+var frameworkPackages = []struct {
+	PackageName string
+	Framework   string
+}{
+	{PackageName: "laravel/framework", Framework: FrameworkLaravel},
+	{PackageName: "symfony/framework-bundle", Framework: FrameworkSymfony},
+	{PackageName: "symfony/symfony", Framework: FrameworkSymfony},
+}
+
 // NewPHP generates a ready-to-use StatePHP
 func NewPHP() (*PHP, error) {
 	languagePHP := &PHP{}
@@ -24,6 +43,14 @@ func NewPHP() (*PHP, error) {
 		return nil, fmt.Errorf("%w while parsing composer.json", err)
 	}
 
+	lockFileBytes, err := ioutil.ReadFile("composer.lock")
+	if err == nil {
+		err = json.Unmarshal(lockFileBytes, &languagePHP.composerLock)
+		if err != nil {
+			return nil, fmt.Errorf("%w while parsing composer.lock", err)
+		}
+	}
+
 	return languagePHP, nil
 }
 
@@ -31,6 +58,45 @@ func NewPHP() (*PHP, error) {
 type PHP struct {
 	Enabled      bool
 	composerJSON ComposerDotJSON
+	composerLock ComposerDotLock
+}
+
+// Version gets the major.minor php version the project targets, or an empty string when unknown
+func (languagePHP PHP) Version() string {
+	// The platform config is what composer resolves dependencies against, so it wins over constraints
+	constraints := []string{
+		languagePHP.composerJSON.Config.Platform["php"],
+		platformPHP(languagePHP.composerLock.PlatformOverrides),
+		languagePHP.composerJSON.Require["php"],
+		platformPHP(languagePHP.composerLock.Platform),
+	}
+
+	var versionMatcher = regexp.MustCompile(`(\d+)(\.(\d+))?`)
+	for _, constraint := range constraints {
+		match := versionMatcher.FindStringSubmatch(constraint)
+		if match == nil {
+			continue
+		}
+
+		minor := match[3]
+		if minor == "" {
+			minor = "0"
+		}
+
+		return match[1] + "." + minor
+	}
+
+	return ""
+}
+
+// VersionID gets the version in the PHP_VERSION_ID format, e.g. 80100 for 8.1
+func (languagePHP PHP) VersionID() int {
+	var major, minor int
+	if _, err := fmt.Sscanf(languagePHP.Version(), "%d.%d", &major, &minor); err != nil {
+		return 0
+	}
+
+	return major*10000 + minor*100
 }
 
 // IsSymfony3 checks if the project is a symfony 3.4 project or not
@@ -45,20 +111,214 @@ func (languagePHP PHP) IsSymfony3() bool {
 	return false
 }
 
+// BinDir gets the directory composer installs vendor binaries into
+func (languagePHP PHP) BinDir() string {
+	if languagePHP.composerJSON.Config.BinDir == "" {
+		return "vendor/bin"
+	}
+
+	return strings.TrimSuffix(languagePHP.composerJSON.Config.BinDir, "/")
+}
+
+// Scripts gets the names of the composer scripts, excluding pre-* and post-* event hooks
+func (languagePHP PHP) Scripts() []string {
+	scripts := []string{}
+	for scriptName := range languagePHP.composerJSON.Scripts {
+		if strings.HasPrefix(scriptName, "pre-") || strings.HasPrefix(scriptName, "post-") {
+			continue
+		}
+
+		scripts = append(scripts, scriptName)
+	}
+
+	sort.Strings(scripts)
+
+	return scripts
+}
+
+// SourcePaths gets the source directories from the PSR-4 and PSR-0 autoload config, defaulting to src
+func (languagePHP PHP) SourcePaths() []string {
+	paths := languagePHP.composerJSON.Autoload.Paths()
+	if len(paths) == 0 {
+		return []string{"src"}
+	}
+
+	return paths
+}
+
+// TestPaths gets the test directories from the autoload-dev config, defaulting to the source directories
+func (languagePHP PHP) TestPaths() []string {
+	paths := languagePHP.composerJSON.AutoloadDev.Paths()
+	if len(paths) == 0 {
+		return languagePHP.SourcePaths()
+	}
+
+	return paths
+}
+
+// Requires checks if a package is in require or require-dev
+func (languagePHP PHP) Requires(packageName string) bool {
+	if _, found := languagePHP.composerJSON.Require[packageName]; found {
+		return true
+	}
+
+	_, found := languagePHP.composerJSON.RequireDev[packageName]
+
+	return found
+}
+
+// Framework detects the framework the project is built on, the name is empty when there is none
+func (languagePHP PHP) Framework() PHPFramework {
+	for _, frameworkPackage := range frameworkPackages {
+		version, found := languagePHP.composerJSON.Require[frameworkPackage.PackageName]
+		if !found {
+			continue
+		}
+
+		// The installed version is more precise than the constraint
+		if installedVersion := languagePHP.composerLock.PackageVersion(frameworkPackage.PackageName); installedVersion != "" {
+			version = installedVersion
+		}
+
+		return PHPFramework{
+			Name:         frameworkPackage.Framework,
+			MajorVersion: majorVersion(version),
+		}
+	}
+
+	return PHPFramework{}
+}
+
+// PackageMajorVersion gets the major version of a package, or 0 when it isn't required
+func (languagePHP PHP) PackageMajorVersion(packageName string) int {
+	if installedVersion := languagePHP.composerLock.PackageVersion(packageName); installedVersion != "" {
+		return majorVersion(installedVersion)
+	}
+
+	if constraint, found := languagePHP.composerJSON.Require[packageName]; found {
+		return majorVersion(constraint)
+	}
+
+	return majorVersion(languagePHP.composerJSON.RequireDev[packageName])
+}
+
+// majorVersion gets the first number of a version or constraint, e.g. 6 for v6.1.3
+func majorVersion(version string) int {
+	var versionMatcher = regexp.MustCompile(`\d+`)
+	major, _ := strconv.Atoi(versionMatcher.FindString(version))
+
+	return major
+}
+
+// PHPFramework is the framework of the php project
+type PHPFramework struct {
+	Name         string
+	MajorVersion int
+}
+
 // ComposerDotJSON is the structure of the composer.json file
 type ComposerDotJSON struct {
-	Autoload   ComposerDotJSONAutoload `json:"autoload"`
-	Require    map[string]string       `json:"require"`
-	RequireDev map[string]string       `json:"require-dev"`
+	Autoload    ComposerDotJSONAutoload `json:"autoload"`
+	AutoloadDev ComposerDotJSONAutoload `json:"autoload-dev"`
+	Config      ComposerDotJSONConfig   `json:"config"`
+	Require     map[string]string       `json:"require"`
+	RequireDev  map[string]string       `json:"require-dev"`
+	// Scripts can be a single command or a list of commands, only the names are used
+	Scripts map[string]interface{} `json:"scripts"`
 }
 
 // ComposerDotJSONConfig is the config struct for the composer.json file
 type ComposerDotJSONConfig struct {
-	BinDir string `json:"bin-dir"`
+	BinDir   string            `json:"bin-dir"`
+	Platform map[string]string `json:"platform"`
 }
 
 // ComposerDotJSONAutoload is the autoload struct for the composer.json file
 type ComposerDotJSONAutoload struct {
-	PSR0 map[string]string `json:"psr-0"`
-	PSR4 map[string]string `json:"psr-4"`
+	PSR0 map[string]ComposerDotJSONAutoloadPaths `json:"psr-0"`
+	PSR4 map[string]ComposerDotJSONAutoloadPaths `json:"psr-4"`
+}
+
+// Paths gets the unique directories of the autoload config, PSR-4 first
+func (autoload ComposerDotJSONAutoload) Paths() []string {
+	paths := []string{}
+	seen := map[string]bool{}
+
+	for _, namespaces := range []map[string]ComposerDotJSONAutoloadPaths{autoload.PSR4, autoload.PSR0} {
+		namespaceNames := []string{}
+		for namespaceName := range namespaces {
+			namespaceNames = append(namespaceNames, namespaceName)
+		}
+		sort.Strings(namespaceNames)
+
+		for _, namespaceName := range namespaceNames {
+			for _, path := range namespaces[namespaceName] {
+				path = strings.TrimSuffix(path, "/")
+				if path == "" {
+					path = "."
+				}
+				if seen[path] {
+					continue
+				}
+
+				seen[path] = true
+				paths = append(paths, path)
+			}
+		}
+	}
+
+	return paths
+}
+
+// ComposerDotJSONAutoloadPaths is the directory, or list of directories, of an autoload namespace
+type ComposerDotJSONAutoloadPaths []string
+
+// UnmarshalJSON accepts both a single directory and a list of directories
+func (paths *ComposerDotJSONAutoloadPaths) UnmarshalJSON(data []byte) error {
+	var path string
+	if err := json.Unmarshal(data, &path); err == nil {
+		*paths = []string{path}
+
+		return nil
+	}
+
+	return json.Unmarshal(data, (*[]string)(paths))
+}
+
+// ComposerDotLock is the structure of the composer.lock file
+type ComposerDotLock struct {
+	Packages    []ComposerDotLockPackage `json:"packages"`
+	PackagesDev []ComposerDotLockPackage `json:"packages-dev"`
+	// Composer writes empty platform maps as [], so these can't be typed as maps
+	Platform          interface{} `json:"platform"`
+	PlatformOverrides interface{} `json:"platform-overrides"`
+}
+
+// PackageVersion gets the installed version of a package
+func (composerLock ComposerDotLock) PackageVersion(packageName string) string {
+	for _, lockedPackage := range append(composerLock.Packages, composerLock.PackagesDev...) {
+		if lockedPackage.Name == packageName {
+			return lockedPackage.Version
+		}
+	}
+
+	return ""
+}
+
+// ComposerDotLockPackage is a package entry in the composer.lock file
+type ComposerDotLockPackage struct {
+	Name    string `json:"name"`
+	Version string `json:"version"`
+}
+
+// platformPHP gets the php entry of a composer.lock platform map
+func platformPHP(platform interface{}) string {
+	values, ok := platform.(map[string]interface{})
+	if !ok {
+		return ""
+	}
+
+	value, _ := values["php"].(string)
+
+	return value
 }
@@ -0,0 +1,128 @@
+package language
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"regexp"
+	"strings"
+)
+
+// NewRuby generates a ready-to-use StateRuby
+func NewRuby() (*Ruby, error) {
+	languageRuby := &Ruby{
+		gems:       make(map[string]bool),
+		lockedGems: make(map[string]bool),
+	}
+
+	fileBytes, err := ioutil.ReadFile("Gemfile")
+	if err != nil {
+		// Gemfile not able to be opened,
+		// this probably means it's not a ruby project
+		return languageRuby, nil
+	}
+
+	languageRuby.Enabled = true
+
+	gemMatcher := regexp.MustCompile(`(?m)^\s*gem\s+['"]([^'"]+)['"]`)
+	for _, match := range gemMatcher.FindAllSubmatch(fileBytes, -1) {
+		languageRuby.gems[string(match[1])] = true
+	}
+
+	versionFileBytes, err := ioutil.ReadFile(".ruby-version")
+	if err == nil {
+		version := strings.TrimSpace(string(versionFileBytes))
+		languageRuby.version = strings.TrimPrefix(version, "ruby-")
+	}
+
+	lockFileBytes, err := ioutil.ReadFile("Gemfile.lock")
+	if err == nil {
+		if err := languageRuby.parseLockFile(lockFileBytes); err != nil {
+			return nil, fmt.Errorf("%w while parsing Gemfile.lock", err)
+		}
+	}
+
+	return languageRuby, nil
+}
+
+// Ruby is the state of the ruby project
+type Ruby struct {
+	Enabled    bool
+	version    string
+	gems       map[string]bool
+	lockedGems map[string]bool
+}
+
+// Version gets the ruby version from .ruby-version or Gemfile.lock
+func (languageRuby *Ruby) Version() string {
+	if languageRuby.version == "" {
+		return "3.0"
+	}
+
+	return languageRuby.version
+}
+
+// HasGem checks if the project depends on a gem
+func (languageRuby *Ruby) HasGem(gemName string) bool {
+	return languageRuby.gems[gemName] || languageRuby.lockedGems[gemName]
+}
+
+// IsRails checks if the project is a rails project or not
+func (languageRuby *Ruby) IsRails() bool {
+	return languageRuby.HasGem("rails") || languageRuby.HasGem("railties")
+}
+
+// TestFramework gets the test framework used by the project, rspec or minitest
+func (languageRuby *Ruby) TestFramework() string {
+	if languageRuby.lockedGems["rspec-core"] || languageRuby.lockedGems["rspec-rails"] {
+		return "rspec"
+	}
+
+	if languageRuby.lockedGems["minitest"] {
+		return "minitest"
+	}
+
+	if languageRuby.gems["rspec"] || languageRuby.gems["rspec-rails"] {
+		return "rspec"
+	}
+
+	return "minitest"
+}
+
+func (languageRuby *Ruby) parseLockFile(fileBytes []byte) error {
+	versionMatcher := regexp.MustCompile(`^\s+ruby (\d+(\.\d+)*)`)
+	section := ""
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := scanner.Text()
+		if line == "" {
+			continue
+		}
+
+		// Section headers are the only lines without indentation
+		if !strings.HasPrefix(line, " ") {
+			section = line
+			continue
+		}
+
+		switch section {
+		case "GEM", "GIT", "PATH":
+			// Resolved gems are indented by exactly four spaces
+			if strings.HasPrefix(line, "    ") && !strings.HasPrefix(line, "     ") {
+				gemName := strings.Fields(line)[0]
+				languageRuby.lockedGems[gemName] = true
+			}
+		case "RUBY VERSION":
+			if languageRuby.version != "" {
+				continue
+			}
+			if match := versionMatcher.FindStringSubmatch(line); match != nil {
+				languageRuby.version = match[1]
+			}
+		}
+	}
+
+	return scanner.Err()
+}
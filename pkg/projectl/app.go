@@ -1,46 +1,223 @@
 package projectl
 
 import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
 	"github.com/aaronellington/projectl/pkg/configuration"
 	"github.com/aaronellington/projectl/pkg/generators"
 	"github.com/aaronellington/projectl/pkg/projector"
 )
 
+// Errors
+var (
+	ErrUnknownCommand = errors.New("unknown command")
+)
+
 // App is the projectl app
-type App struct{}
+type App struct {
+	Output io.Writer
+	// ErrorOutput gets the warnings, so they don't end up in the output of commands like config show
+	ErrorOutput io.Writer
+	// Overrides are set by flags and win over the environment variables
+	Overrides []configuration.Override
+}
+
+// Run the command given on the command line, generating the project files when there is none
+func (app *App) Run(args []string) error {
+	flags := flag.NewFlagSet("projectl", flag.ContinueOnError)
+	flags.SetOutput(app.output())
+	flags.Var(&overrideFlag{app: app}, "set", "override a config value, like --set docker_port=9000 or --set generators.php_config.phpstan.level=5")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	args = flags.Args()
+	if len(args) == 0 {
+		return app.Execute()
+	}
+
+	switch args[0] {
+	case "validate":
+		return app.Validate()
+	case "schema":
+		return app.Schema()
+	case "env":
+		return app.Env(args[1:])
+	case "config":
+		if len(args) > 1 && args[1] == "show" {
+			return app.ConfigShow(args[2:])
+		}
+
+		if len(args) > 1 && args[1] == "migrate" {
+			return app.ConfigMigrate(args[2:])
+		}
+
+		return fmt.Errorf("%w: %s", ErrUnknownCommand, strings.Join(args, " "))
+	}
+
+	return fmt.Errorf("%w: %s", ErrUnknownCommand, args[0])
+}
 
 // Execute the app
 func (app *App) Execute() error {
-	config, err := configuration.NewConfig(".projectl.json")
+	config, service, err := app.load()
 	if err != nil {
 		return err
 	}
 
-	service, err := projector.NewService()
+	generatorList := []struct {
+		Enabled   bool
+		Generator projector.Generator
+	}{
+		{config.Generators.Gitignore.IsEnabled(), generators.NewGitignore(service, config)},
+		{config.Generators.Makefile.IsEnabled(), generators.NewMakefile(service, config)},
+		{config.Generators.GithubWorkflow.IsEnabled(), &generators.GithubWorkflow{}},
+		{config.Generators.Eslint.IsEnabled(), &generators.EslintGenerator{
+			Config: config.Generators.Eslint,
+		}},
+		{config.Generators.TypeScript.IsEnabled(), &generators.TypeScriptGenerator{
+			Config: config.Generators.TypeScript,
+		}},
+		{config.Generators.Prettier.IsEnabled(), &generators.PrettierGenerator{
+			Config: config.Generators.Prettier.Options,
+		}},
+		{config.Generators.Stylelint.IsEnabled(), &generators.StylelintGenerator{
+			Config: config.Generators.Stylelint,
+		}},
+		{config.Generators.Editorconfig.IsEnabled(), &generators.EditorconfigGenerator{
+			Config: config.Generators.Editorconfig.Sections,
+		}},
+		{config.Generators.PHPConfig.IsEnabled(), &generators.PHPConfig{
+			PHPCSFixer: config.Generators.PHPConfig.PHPCSFixer,
+			PHPStan:    config.Generators.PHPConfig.PHPStan,
+			PHPUnit:    config.Generators.PHPConfig.PHPUnit,
+		}},
+		{config.Generators.Dockerfile.IsEnabled() && config.DockerName != "", &generators.Dockerfile{
+			Port:   config.DockerPort,
+			Target: config.DockerTarget,
+		}},
+	}
+
+	for _, candidate := range generatorList {
+		if candidate.Enabled {
+			service.Generators = append(service.Generators, candidate.Generator)
+		}
+	}
+
+	return service.Generate()
+}
+
+// Validate the config file without generating anything
+func (app *App) Validate() error {
+	if _, _, err := app.load(); err != nil {
+		return err
+	}
+
+	_, _ = fmt.Fprintf(app.output(), "%s is valid\n", configuration.FindConfigFile())
+
+	return nil
+}
+
+// Schema prints the JSON Schema of the config file
+func (app *App) Schema() error {
+	schemaBytes, err := json.MarshalIndent(configuration.NewSchema(), "", "\t")
 	if err != nil {
 		return err
 	}
 
-	config.DistedFiles = append(config.DistedFiles, "/app/config/parameters.yml")
-	config.DistedFiles = append(config.DistedFiles, "/.env")
+	_, _ = fmt.Fprintf(app.output(), "%s\n", schemaBytes)
 
-	service.DistedFiles = config.DistedFiles
+	return nil
+}
 
-	service.Generators = append(service.Generators, []projector.Generator{
-		generators.NewGitignore(service, config),
-		generators.NewMakefile(service, config),
-		&generators.GithubWorkflow{},
-		&generators.EslintGenerator{},
-		&generators.PHPConfig{},
-	}...)
+func (app *App) load() (*configuration.Config, *projector.Service, error) {
+	resolvedConfig, err := app.resolveConfig()
+	if err != nil {
+		return nil, nil, err
+	}
+	config := resolvedConfig.Config
 
-	if config.DockerName != "" {
-		service.Generators = append(service.Generators, &generators.Dockerfile{
-			Port:   config.DockerPort,
-			Target: config.DockerTarget,
-			Custom: config.CustomDockerFile,
-		})
+	service, err := projector.NewService()
+	if err != nil {
+		return nil, nil, err
 	}
 
-	return service.Generate()
+	if err := config.Validate(service); err != nil {
+		return nil, nil, err
+	}
+
+	// The config can add to the defaults, or remove them with a ! pattern
+	distedFiles, err := projector.ResolveDistedFiles(append(service.DefaultDistedFiles(), config.DistedFiles...))
+	if err != nil {
+		return nil, nil, err
+	}
+	service.DistedFiles = distedFiles
+
+	if config.NodeVersion != "" {
+		service.Npm.SetVersion(config.NodeVersion)
+	}
+
+	return config, service, nil
+}
+
+// resolveConfig merges the config files, then the environment variables, then the flags
+func (app *App) resolveConfig() (*configuration.ResolvedConfig, error) {
+	overrides := append(configuration.EnvOverrides(os.Environ()), app.Overrides...)
+
+	resolvedConfig, err := configuration.ResolveConfig(configuration.FindConfigFile(), overrides)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, warning := range resolvedConfig.Warnings {
+		_, _ = fmt.Fprintf(app.errorOutput(), "warning: %s\n", warning)
+	}
+
+	return resolvedConfig, nil
+}
+
+func (app *App) output() io.Writer {
+	if app.Output == nil {
+		return os.Stdout
+	}
+
+	return app.Output
+}
+
+func (app *App) errorOutput() io.Writer {
+	if app.ErrorOutput == nil {
+		return os.Stderr
+	}
+
+	return app.ErrorOutput
+}
+
+// overrideFlag collects the repeatable --set flag
+type overrideFlag struct {
+	app *App
+}
+
+func (setFlag *overrideFlag) String() string {
+	return ""
+}
+
+func (setFlag *overrideFlag) Set(value string) error {
+	parts := strings.SplitN(value, "=", 2)
+	if len(parts) != 2 {
+		return fmt.Errorf("expected key=value, got %q", value)
+	}
+
+	setFlag.app.Overrides = append(setFlag.app.Overrides, configuration.Override{
+		Path:   parts[0],
+		Value:  parts[1],
+		Source: "flag --set " + parts[0],
+	})
+
+	return nil
 }
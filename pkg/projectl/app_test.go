@@ -1,14 +1,18 @@
 package projectl_test
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"io/ioutil"
 	"os"
 	"path"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/aaronellington/projectl/pkg/configuration"
+	"github.com/aaronellington/projectl/pkg/generators"
 	"github.com/aaronellington/projectl/pkg/projectl"
 )
 
@@ -17,6 +21,29 @@ type TestCase struct {
 	ExpectedError error
 }
 
+// packagePath is resolved before any test changes the working directory
+var packagePath, _ = os.Getwd()
+
+func TestSchema(t *testing.T) {
+	output := &bytes.Buffer{}
+	app := projectl.App{
+		Output: output,
+	}
+
+	if err := app.Schema(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	publishedBytes, err := ioutil.ReadFile(packagePath + "/../../projectl.schema.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !bytes.Equal(output.Bytes(), publishedBytes) {
+		t.Fatal("projectl.schema.json is out of date, regenerate it with projectl schema")
+	}
+}
+
 func TestExecute(t *testing.T) {
 	testCases := []TestCase{
 		{
@@ -27,14 +54,94 @@ func TestExecute(t *testing.T) {
 			Path:          buildPath("full_php"),
 			ExpectedError: nil,
 		},
+		{
+			Path:          buildPath("full_symfony"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_laravel"),
+			ExpectedError: nil,
+		},
 		{
 			Path:          buildPath("full_npm"),
 			ExpectedError: nil,
 		},
+		{
+			Path:          buildPath("full_ruby"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_yarn"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_npm_lockfile_v3"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_node"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_node_lockfile"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_react"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_turbo"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("full_pnpm_workspace"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("yaml_config"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("toml_config"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("extends_config"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("disted_files"),
+			ExpectedError: nil,
+		},
+		{
+			Path:          buildPath("generators_disabled"),
+			ExpectedError: nil,
+		},
 		{
 			Path:          buildPath("invalid_config_file"),
 			ExpectedError: configuration.ErrInvalidConfigFile,
 		},
+		{
+			Path:          buildPath("invalid_config_key"),
+			ExpectedError: configuration.ErrInvalidConfigFile,
+		},
+		{
+			Path:          buildPath("invalid_config_values"),
+			ExpectedError: configuration.ErrInvalidConfig,
+		},
+		{
+			Path:          buildPath("invalid_config_yaml"),
+			ExpectedError: configuration.ErrInvalidConfigFile,
+		},
+		{
+			Path:          buildPath("invalid_config_extends"),
+			ExpectedError: configuration.ErrInvalidConfigFile,
+		},
+		{
+			Path:          buildPath("invalid_eslint_flat_config"),
+			ExpectedError: generators.ErrMissingDependencies,
+		},
 	}
 
 	for _, testCase := range testCases {
@@ -45,8 +152,250 @@ func TestExecute(t *testing.T) {
 	}
 }
 
+func TestStaleConfigFiles(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	handWrittenConfig := "export default [];\n"
+	_ = ioutil.WriteFile("eslint.config.mjs", []byte(handWrittenConfig), 0644)
+	_ = ioutil.WriteFile("package.json", []byte(`{"devDependencies": {"eslint": "^8.57.0"}}`), 0644)
+
+	app := projectl.App{}
+
+	if err := app.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	// ESLint 8 can read a flat config, so one written by hand is kept
+	configBytes, _ := ioutil.ReadFile("eslint.config.mjs")
+	if string(configBytes) != handWrittenConfig {
+		t.Fatalf("Hand-written eslint.config.mjs was changed: %s", configBytes)
+	}
+
+	_ = ioutil.WriteFile("package.json", []byte(`{"devDependencies": {"@eslint/eslintrc": "^3.0.2", "@eslint/js": "^9.1.1", "eslint": "^9.0.0", "globals": "^15.1.0"}}`), 0644)
+	if err := app.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat(".eslintrc.json"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Generated .eslintrc.json was not removed: %v", err)
+	}
+
+	_ = ioutil.WriteFile("package.json", []byte(`{"devDependencies": {"eslint": "^8.57.0"}}`), 0644)
+	if err := app.Execute(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	if _, err := os.Stat("eslint.config.mjs"); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Generated eslint.config.mjs was not removed: %v", err)
+	}
+}
+
+func TestEnv(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	_ = ioutil.WriteFile(".env.dist", []byte("# Local settings\nAPP_ENV=dev\nDATABASE_URL=mysql://localhost\n"), 0644)
+	_ = ioutil.WriteFile("app.env.example", []byte("PORT=8000\n"), 0644)
+	_ = ioutil.WriteFile(".projectl.json", []byte(`{"disted_files": ["/app.env"]}`), 0644)
+	_ = ioutil.WriteFile(".env", []byte("APP_ENV=prod\nLEGACY=1"), 0644)
+
+	output := &bytes.Buffer{}
+	app := projectl.App{
+		Output: output,
+	}
+
+	if err := app.Env(nil); !errors.Is(err, projectl.ErrMissingEnvKeys) {
+		t.Fatalf("Incorrect error: %v Got: %v", projectl.ErrMissingEnvKeys, err)
+	}
+
+	expectedOutput := `.env is missing DATABASE_URL
+.env has LEGACY which is not in .env.dist
+Copied app.env.example to app.env
+`
+	if output.String() != expectedOutput {
+		t.Fatalf("Unexpected output: %s", output.String())
+	}
+
+	if err := app.Env([]string{"-append"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	envBytes, _ := ioutil.ReadFile(".env")
+	if string(envBytes) != "APP_ENV=prod\nLEGACY=1\nDATABASE_URL=mysql://localhost\n" {
+		t.Fatalf("Missing keys not appended: %s", envBytes)
+	}
+}
+
+func TestConfigShow(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	_ = ioutil.WriteFile("base.json", []byte(`{"docker_port": 8000, "gitignore": ["/coverage/"]}`), 0644)
+	_ = ioutil.WriteFile(".projectl.json", []byte(`{"extends": "base.json", "docker_name": "app", "gitignore": ["/uploads/"]}`), 0644)
+	t.Setenv("PROJECTL_DOCKER_PORT", "9000")
+	t.Setenv("PROJECTL_DOCKER_NAME", "from-env")
+	t.Setenv("PROJECTL_VERSION", "1.2.3")
+
+	output := &bytes.Buffer{}
+	errorOutput := &bytes.Buffer{}
+	app := projectl.App{
+		Output:      output,
+		ErrorOutput: errorOutput,
+	}
+
+	if err := app.Run([]string{"--set", "docker_name=from-flag", "config", "show", "--resolved"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedLines := []string{
+		`docker_name                              "from-flag"                 flag --set docker_name`,
+		`docker_port                              9000                        env PROJECTL_DOCKER_PORT`,
+		`gitignore                                ["/coverage/","/uploads/"]  base.json, .projectl.json`,
+		`node_version                             "16"                        default`,
+	}
+	for _, expectedLine := range expectedLines {
+		if !strings.Contains(output.String(), expectedLine+"\n") {
+			t.Fatalf("Missing line %q in output:\n%s", expectedLine, output.String())
+		}
+	}
+
+	// Other tools share the prefix, so an env variable that doesn't fit the config is only a warning
+	expectedWarning := "warning: env PROJECTL_VERSION: \"1.2.3\" is not a number, skipped\n"
+	if errorOutput.String() != expectedWarning {
+		t.Fatalf("Unexpected warnings: %s", errorOutput.String())
+	}
+
+	if err := app.Run([]string{"--set", "home=/x", "config", "show"}); !errors.Is(err, configuration.ErrInvalidConfigFile) {
+		t.Fatalf("Incorrect error: %v Got: %v", configuration.ErrInvalidConfigFile, err)
+	}
+}
+
+func TestConfigOverrideList(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	_ = ioutil.WriteFile(".projectl.json", []byte(`{"gitignore": ["/x"]}`), 0644)
+	t.Setenv("PROJECTL_GITIGNORE", "/a,/b")
+
+	// Overrides replace the lists the config files append to
+	testCases := []struct {
+		Args     []string
+		Expected []string
+	}{
+		{[]string{"config", "show"}, []string{"/a", "/b"}},
+		{[]string{"--set", "gitignore=/c", "config", "show"}, []string{"/c"}},
+	}
+
+	for _, testCase := range testCases {
+		output := &bytes.Buffer{}
+		app := projectl.App{
+			Output:      output,
+			ErrorOutput: &bytes.Buffer{},
+		}
+
+		if err := app.Run(testCase.Args); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		values := struct {
+			Gitignore []string `json:"gitignore"`
+		}{}
+		if err := json.Unmarshal(output.Bytes(), &values); err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+
+		if !reflect.DeepEqual(values.Gitignore, testCase.Expected) {
+			t.Fatalf("Unexpected gitignore for %v: %v", testCase.Args, values.Gitignore)
+		}
+	}
+}
+
+func TestConfigMigrate(t *testing.T) {
+	chdir(t, t.TempDir())
+
+	_ = ioutil.WriteFile(".projectl.json", []byte(`{
+    "go_http": true,
+    "docker_name": "app",
+    "docker_port": 8000,
+    "custom_dockerfile": true,
+    "gitignore": ["/coverage/"],
+    "phpstan": {"level": "5"}
+}
+`), 0644)
+
+	errorOutput := &bytes.Buffer{}
+	app := projectl.App{
+		Output:      &bytes.Buffer{},
+		ErrorOutput: errorOutput,
+	}
+
+	if err := app.Validate(); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedWarnings := `warning: .projectl.json: custom_dockerfile is deprecated, use generators.dockerfile.enabled instead or run projectl config migrate
+warning: .projectl.json: go_http is deprecated, use generators.makefile.go_http instead or run projectl config migrate
+warning: .projectl.json: phpstan is deprecated, use generators.php_config.phpstan instead or run projectl config migrate
+`
+	if errorOutput.String() != expectedWarnings {
+		t.Fatalf("Unexpected warnings: %s", errorOutput.String())
+	}
+
+	if err := app.Run([]string{"config", "migrate"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedConfig := `{
+    "version": 3,
+    "docker_name": "app",
+    "docker_port": 8000,
+    "generators": {
+        "dockerfile": {
+            "enabled": false
+        },
+        "makefile": {
+            "go_http": true
+        },
+        "php_config": {
+            "phpstan": {"level": "5"}
+        }
+    },
+    "gitignore": ["/coverage/"]
+}
+`
+	configBytes, _ := ioutil.ReadFile(".projectl.json")
+	if string(configBytes) != expectedConfig {
+		t.Fatalf("Unexpected migrated config: %s", configBytes)
+	}
+
+	errorOutput.Reset()
+	if err := app.Validate(); err != nil || errorOutput.Len() > 0 {
+		t.Fatalf("Migrated config is not clean: %v %s", err, errorOutput.String())
+	}
+
+	// A deprecated key is still moved at the current version, without overriding the explicit values
+	_ = ioutil.WriteFile(".projectl.json", []byte(`{
+    "version": 3,
+    "custom_dockerfile": true,
+    "prettier": {"semi": false, "printWidth": 100},
+    "generators": {"dockerfile": {"enabled": true}, "prettier": {"options": {"semi": true}}}
+}
+`), 0644)
+
+	if err := app.Run([]string{"config", "migrate"}); err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+
+	expectedConfig = `{
+    "version": 3,
+    "generators": {"dockerfile": {"enabled": true}, "prettier": {"options": {"semi": true, "printWidth": 100}}}
+}
+`
+	configBytes, _ = ioutil.ReadFile(".projectl.json")
+	if string(configBytes) != expectedConfig {
+		t.Fatalf("Unexpected migrated config: %s", configBytes)
+	}
+}
+
 func testProject(t *testing.T, testCase TestCase) {
-	_ = os.Chdir(testCase.Path)
+	chdir(t, testCase.Path)
 	_, _ = os.Create("Dockerfile")
 
 	app := projectl.App{}
@@ -75,11 +424,49 @@ func testProject(t *testing.T, testCase TestCase) {
 			t.Fatal(err)
 		}
 	}
+
+	// Only compared for the test projects that provide a target
+	optionalFilesToCompare := []string{
+		".eslintrc.json",
+		"eslint.config.mjs",
+		".prettierrc",
+		".stylelintrc.json",
+		".editorconfig",
+		"tsconfig.json",
+		".php-cs-fixer.dist.php",
+		"phpstan.neon.dist",
+		"phpunit.xml.dist",
+	}
+
+	for _, fileToCompare := range optionalFilesToCompare {
+		if _, err := os.Stat(fileToCompare + "-target"); err != nil {
+			continue
+		}
+
+		if err := compareTwoFiles(fileToCompare); err != nil {
+			t.Fatal(err)
+		}
+	}
 }
 
 func buildPath(testName string) string {
-	wd, _ := os.Getwd()
-	return wd + "/test_projects/" + testName
+	return packagePath + "/test_projects/" + testName
+}
+
+// chdir changes the working directory until the test ends
+func chdir(t *testing.T, dir string) {
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Cleanup(func() {
+		_ = os.Chdir(wd)
+	})
 }
 
 func compareTwoFiles(sourcePath string) error {
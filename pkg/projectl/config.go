@@ -0,0 +1,78 @@
+package projectl
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"text/tabwriter"
+
+	"github.com/aaronellington/projectl/pkg/configuration"
+)
+
+// ConfigShow prints the effective config, -resolved lists every value with where it came from
+func (app *App) ConfigShow(args []string) error {
+	flags := flag.NewFlagSet("config show", flag.ContinueOnError)
+	flags.SetOutput(app.output())
+	resolved := flags.Bool("resolved", false, "list every value with the file, environment variable, flag or default it came from")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	resolvedConfig, err := app.resolveConfig()
+	if err != nil {
+		return err
+	}
+
+	if !*resolved {
+		valuesBytes, err := json.MarshalIndent(resolvedConfig.Values, "", "\t")
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(app.output(), "%s\n", valuesBytes)
+
+		return nil
+	}
+
+	writer := tabwriter.NewWriter(app.output(), 0, 4, 2, ' ', 0)
+	for _, entry := range resolvedConfig.Entries() {
+		valueBytes, err := json.Marshal(entry.Value)
+		if err != nil {
+			return err
+		}
+
+		_, _ = fmt.Fprintf(writer, "%s\t%s\t%s\n", entry.Path, valueBytes, entry.Source)
+	}
+
+	return writer.Flush()
+}
+
+// ConfigMigrate rewrites the config file, or the file given like a shared base config, to the current version
+func (app *App) ConfigMigrate(args []string) error {
+	configFilePath := configuration.FindConfigFile()
+	if len(args) > 0 {
+		configFilePath = args[0]
+	}
+
+	fileBytes, err := ioutil.ReadFile(configFilePath)
+	if err != nil {
+		return fmt.Errorf("%w while reading %s", err, configFilePath)
+	}
+
+	migratedBytes, changes, err := configuration.MigrateConfig(configFilePath, fileBytes)
+	for _, change := range changes {
+		_, _ = fmt.Fprintf(app.output(), "%s: %s\n", configFilePath, change)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(changes) == 0 {
+		_, _ = fmt.Fprintf(app.output(), "%s is already at version %d\n", configFilePath, configuration.CurrentVersion)
+
+		return nil
+	}
+
+	return ioutil.WriteFile(configFilePath, migratedBytes, 0644)
+}
@@ -0,0 +1,171 @@
+package projectl
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/aaronellington/projectl/pkg/projector"
+)
+
+// Errors
+var (
+	ErrMissingEnvKeys = errors.New("env files are missing keys")
+)
+
+// Env copies the missing disted files into place and compares the keys of the
+// env files with their templates, -append adds the missing keys with their defaults
+func (app *App) Env(args []string) error {
+	flags := flag.NewFlagSet("env", flag.ContinueOnError)
+	flags.SetOutput(app.output())
+	appendMissing := flags.Bool("append", false, "append the missing keys with the defaults from the template")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	_, service, err := app.load()
+	if err != nil {
+		return err
+	}
+
+	missingKeys := false
+	for _, distedFile := range service.DistedFiles {
+		templateBytes, err := ioutil.ReadFile(distedFile.LocalTemplate())
+		if err != nil {
+			return fmt.Errorf("%w while reading %s", err, distedFile.LocalTemplate())
+		}
+
+		localBytes, err := ioutil.ReadFile(distedFile.LocalPath())
+		if errors.Is(err, os.ErrNotExist) {
+			if err := ioutil.WriteFile(distedFile.LocalPath(), templateBytes, 0644); err != nil {
+				return fmt.Errorf("%w while copying %s", err, distedFile.LocalTemplate())
+			}
+
+			_, _ = fmt.Fprintf(app.output(), "Copied %s to %s\n", distedFile.LocalTemplate(), distedFile.LocalPath())
+
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("%w while reading %s", err, distedFile.LocalPath())
+		}
+
+		if !isEnvFile(distedFile) {
+			continue
+		}
+
+		missing, appended, err := app.compareEnvFile(distedFile, templateBytes, localBytes, *appendMissing)
+		if err != nil {
+			return err
+		}
+
+		if missing && !appended {
+			missingKeys = true
+		}
+	}
+
+	if missingKeys {
+		return fmt.Errorf("%w, run projectl env -append to add them", ErrMissingEnvKeys)
+	}
+
+	return nil
+}
+
+// compareEnvFile reports the keys only one of the files has, and appends the missing ones when asked to
+func (app *App) compareEnvFile(distedFile projector.DistedFile, templateBytes []byte, localBytes []byte, appendMissing bool) (bool, bool, error) {
+	templateEntries := parseEnvFile(templateBytes)
+	localEntries := parseEnvFile(localBytes)
+
+	missingLines := []string{}
+	for _, entry := range templateEntries {
+		if _, exists := localEntries.line(entry.key); exists {
+			continue
+		}
+
+		missingLines = append(missingLines, entry.line)
+		_, _ = fmt.Fprintf(app.output(), "%s is missing %s\n", distedFile.LocalPath(), entry.key)
+	}
+
+	for _, entry := range localEntries {
+		if _, exists := templateEntries.line(entry.key); !exists {
+			_, _ = fmt.Fprintf(app.output(), "%s has %s which is not in %s\n", distedFile.LocalPath(), entry.key, distedFile.LocalTemplate())
+		}
+	}
+
+	if len(missingLines) == 0 || !appendMissing {
+		return len(missingLines) > 0, false, nil
+	}
+
+	file, err := os.OpenFile(distedFile.LocalPath(), os.O_APPEND|os.O_WRONLY, 0)
+	if err != nil {
+		return true, false, fmt.Errorf("%w while opening %s", err, distedFile.LocalPath())
+	}
+	defer file.Close()
+
+	content := strings.Join(missingLines, "\n") + "\n"
+	if len(localBytes) > 0 && !bytes.HasSuffix(localBytes, []byte("\n")) {
+		content = "\n" + content
+	}
+
+	if _, err := file.WriteString(content); err != nil {
+		return true, false, fmt.Errorf("%w while writing %s", err, distedFile.LocalPath())
+	}
+
+	_, _ = fmt.Fprintf(app.output(), "Appended %d keys to %s\n", len(missingLines), distedFile.LocalPath())
+
+	return true, true, nil
+}
+
+// isEnvFile checks if the disted file holds KEY=value lines, like .env or app.env
+func isEnvFile(distedFile projector.DistedFile) bool {
+	name := path.Base(distedFile.Path)
+
+	return strings.HasPrefix(name, ".env") || strings.HasSuffix(name, ".env")
+}
+
+type envEntry struct {
+	key  string
+	line string
+}
+
+type envEntries []envEntry
+
+func (entries envEntries) line(key string) (string, bool) {
+	for _, entry := range entries {
+		if entry.key == key {
+			return entry.line, true
+		}
+	}
+
+	return "", false
+}
+
+// parseEnvFile gets the keys of a dotenv file in order, skipping comments and blank lines
+func parseEnvFile(fileBytes []byte) envEntries {
+	entries := envEntries{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(fileBytes))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		parts := strings.SplitN(strings.TrimPrefix(line, "export "), "=", 2)
+		if len(parts) != 2 {
+			continue
+		}
+
+		entries = append(entries, envEntry{
+			key:  strings.TrimSpace(parts[0]),
+			line: line,
+		})
+	}
+
+	return entries
+}
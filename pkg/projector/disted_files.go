@@ -0,0 +1,104 @@
+package projector
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DistSuffixes are the suffixes of the committed templates of disted files, in order of preference
+var DistSuffixes = []string{
+	".dist",
+	".example",
+	".sample",
+	".template",
+}
+
+// DistedFile is a local config file copied from a committed template
+type DistedFile struct {
+	// Path from the project root with a leading slash, as used in .gitignore
+	Path string
+	// Template is the path of the committed template the file is copied from
+	Template string
+}
+
+// LocalPath gets the path relative to the project root
+func (distedFile DistedFile) LocalPath() string {
+	return strings.TrimPrefix(distedFile.Path, "/")
+}
+
+// LocalTemplate gets the template path relative to the project root
+func (distedFile DistedFile) LocalTemplate() string {
+	return strings.TrimPrefix(distedFile.Template, "/")
+}
+
+// DefaultDistedFiles gets the disted files the detected languages and frameworks use
+func (service *Service) DefaultDistedFiles() []string {
+	distedFiles := []string{
+		"/.env",
+	}
+
+	if service.PHP.Enabled && service.PHP.IsSymfony3() {
+		distedFiles = append(distedFiles, "/app/config/parameters.yml")
+	}
+
+	if service.Ruby.Enabled && service.Ruby.IsRails() {
+		distedFiles = append(distedFiles, "/config/database.yml")
+	}
+
+	return distedFiles
+}
+
+// ResolveDistedFiles finds the templates of the disted file patterns, patterns can be
+// globs and a pattern starting with ! removes the files it matches from the earlier ones
+func ResolveDistedFiles(patterns []string) ([]DistedFile, error) {
+	distedFiles := map[string]DistedFile{}
+
+	for _, pattern := range patterns {
+		if strings.HasPrefix(pattern, "!") {
+			excludePattern := "/" + strings.TrimPrefix(strings.TrimPrefix(pattern, "!"), "/")
+			for path := range distedFiles {
+				if matched, _ := filepath.Match(excludePattern, path); matched {
+					delete(distedFiles, path)
+				}
+			}
+
+			continue
+		}
+
+		pattern = strings.TrimPrefix(pattern, "/")
+
+		for _, suffix := range DistSuffixes {
+			templates, err := filepath.Glob(pattern + suffix)
+			if err != nil {
+				return nil, fmt.Errorf("%w in disted file %s", err, pattern)
+			}
+
+			for _, template := range templates {
+				path := "/" + filepath.ToSlash(strings.TrimSuffix(template, suffix))
+
+				// The first suffix found wins, .env.dist over .env.example
+				if _, exists := distedFiles[path]; exists {
+					continue
+				}
+
+				distedFiles[path] = DistedFile{
+					Path:     path,
+					Template: "/" + filepath.ToSlash(template),
+				}
+			}
+		}
+	}
+
+	resolved := []DistedFile{}
+	for _, distedFile := range distedFiles {
+		resolved = append(resolved, distedFile)
+	}
+
+	sort.Slice(resolved, func(i, j int) bool {
+		return resolved[i].Path < resolved[j].Path
+	})
+
+	return resolved, nil
+}
@@ -21,20 +21,27 @@ func NewService() (*Service, error) {
 		return nil, err
 	}
 
+	languageRuby, err := language.NewRuby()
+	if err != nil {
+		return nil, err
+	}
+
 	return &Service{
-		Go:  languageGo,
-		PHP: languagePHP,
-		Npm: languageNpm,
+		Go:   languageGo,
+		PHP:  languagePHP,
+		Npm:  languageNpm,
+		Ruby: languageRuby,
 	}, nil
 }
 
 // Service is a projector
 type Service struct {
 	Generators  []Generator
-	DistedFiles []string
+	DistedFiles []DistedFile
 	Go          *language.Go
 	Npm         *language.Npm
 	PHP         *language.PHP
+	Ruby        *language.Ruby
 }
 
 // Generate your thing